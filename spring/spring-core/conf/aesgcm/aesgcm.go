@@ -0,0 +1,112 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package aesgcm 使用 AES-GCM 算法加解密属性值，密钥是 base64 编码的 16、24 或
+// 者 32 字节数据，密文是 base64 编码的随机 nonce 和加密结果。
+package aesgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Cipher 使用 AES-GCM 算法的属性值加解密器，实现了 conf.Decryptor 接口。
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New 返回使用 key 作为密钥的 Cipher 对象，key 的长度必须是 16、24 或者 32 字节。
+func New(key []byte) (*Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// NewBase64 返回使用 base64 编码的 key 作为密钥的 Cipher 对象。
+func NewBase64(key string) (*Cipher, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return New(b)
+}
+
+// NewFromEnv 返回使用环境变量 name 中保存的 base64 编码的密钥的 Cipher 对象。
+func NewFromEnv(name string) (*Cipher, error) {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("env %s not set", name)
+	}
+	return NewBase64(s)
+}
+
+// NewFromFile 返回使用文件 file 中保存的 base64 编码的密钥的 Cipher 对象。
+func NewFromFile(file string) (*Cipher, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewBase64(string(b))
+}
+
+// GenerateKey 返回随机生成的 base64 编码的 32 字节密钥。
+func GenerateKey() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b[:]), nil
+}
+
+// Encrypt 加密属性值，返回 ENC(...) 形式的加密属性值。
+func (c *Cipher) Encrypt(s string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	b := c.aead.Seal(nonce, nonce, []byte(s), nil)
+	return "ENC(" + base64.StdEncoding.EncodeToString(b) + ")", nil
+}
+
+// Decrypt 解密 ENC(...) 括号中的密文，出错时不会返回任何明文信息。
+func (c *Cipher) Decrypt(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", errors.New("invalid ciphertext")
+	}
+	n := c.aead.NonceSize()
+	if len(b) < n {
+		return "", errors.New("invalid ciphertext")
+	}
+	r, err := c.aead.Open(nil, b[:n], b[n:], nil)
+	if err != nil {
+		return "", errors.New("decrypt failed, wrong key or corrupted ciphertext")
+	}
+	return string(r), nil
+}
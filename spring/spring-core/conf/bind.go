@@ -17,6 +17,7 @@
 package conf
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -32,16 +33,18 @@ var (
 )
 
 type bindOption struct {
-	typ    reflect.Type // 绑定对象的类型
-	key    string       // 完整的属性名
-	path   string       // 绑定对象的路径
-	def    string       // 默认值
-	hasDef bool         // 是否具有默认值
+	typ      reflect.Type   // 绑定对象的类型
+	key      string         // 完整的属性名
+	path     string         // 绑定对象的路径
+	def      string         // 默认值
+	hasDef   bool           // 是否具有默认值
+	validate string         // 校验规则
+	errs     *ValidateError // 收集校验失败的信息
 }
 
 func bind(p *Properties, v reflect.Value, tag string, opt bindOption) error {
 
-	if !util.IsValueType(opt.typ) {
+	if !isValueType(opt.typ, p.converter) {
 		return fmt.Errorf("%s 属性绑定的目标必须是值类型", opt.path)
 	}
 
@@ -51,7 +54,17 @@ func bind(p *Properties, v reflect.Value, tag string, opt bindOption) error {
 
 	key, def, hasDef := parseTag(tag)
 	if key == "" {
-		key = "ANONYMOUS"
+		// 省略属性名的结构体从根结点开始绑定，这样字段可以使用完整的属性名。
+		if opt.key == "" && opt.typ.Kind() == reflect.Struct {
+			key = RootKey
+		} else {
+			key = "ANONYMOUS"
+		}
+	}
+
+	// 字段声明的属性名采用宽松匹配，之后的子属性使用属性树中实际的写法。
+	if key != RootKey && key != "ANONYMOUS" {
+		key = p.fieldKey(opt.key, key)
 	}
 
 	if opt.key == "" {
@@ -63,25 +76,26 @@ func bind(p *Properties, v reflect.Value, tag string, opt bindOption) error {
 	opt.def = def
 	opt.hasDef = hasDef
 
-	return bindValue(p, v, opt)
+	if err := bindValue(p, v, opt); err != nil {
+		return err
+	}
+	return validate(v, opt)
 }
 
 func bindValue(p *Properties, v reflect.Value, opt bindOption) error {
 
 	log.Tracef("::<>:: %#v", opt)
 
-	switch v.Kind() {
-	case reflect.Map:
-		return bindMap(p, v, opt)
-	case reflect.Array:
-		return bindArray(p, v, opt)
-	case reflect.Slice:
-		return bindSlice(p, v, opt)
-	}
-
-	fn, _ := converters[opt.typ]
-	if v.Kind() == reflect.Struct {
-		if fn == nil {
+	fn := p.converter(opt.typ)
+	if fn == nil {
+		switch v.Kind() {
+		case reflect.Map:
+			return bindMap(p, v, opt)
+		case reflect.Array:
+			return bindArray(p, v, opt)
+		case reflect.Slice:
+			return bindSlice(p, v, opt)
+		case reflect.Struct:
 			return bindStruct(p, v, opt)
 		}
 	}
@@ -92,12 +106,11 @@ func bindValue(p *Properties, v reflect.Value, opt bindOption) error {
 	}
 
 	if fn != nil {
-		fnValue := reflect.ValueOf(fn)
-		out := fnValue.Call([]reflect.Value{reflect.ValueOf(val)})
-		if !out[1].IsNil() {
-			return out[1].Interface().(error)
+		r, err := fn(val)
+		if err != nil {
+			return fmt.Errorf("%s 属性值转换失败: %w", opt.path, err)
 		}
-		v.Set(out[0])
+		v.Set(r)
 		return nil
 	}
 
@@ -139,11 +152,9 @@ func bindValue(p *Properties, v reflect.Value, opt bindOption) error {
 
 func bindArray(p *Properties, v reflect.Value, opt bindOption) error {
 
-	if opt.hasDef {
-		if opt.def == "" {
-			return nil
-		}
-		return fmt.Errorf("%s array 类型不能指定非空默认值", opt.path)
+	p, err := expand(p, opt)
+	if p == nil || err != nil {
+		return err
 	}
 
 	for i := 0; i < v.Len(); i++ {
@@ -156,6 +167,7 @@ func bindArray(p *Properties, v reflect.Value, opt bindOption) error {
 			typ:  subValue.Type(),
 			key:  subKey,
 			path: subPath,
+			errs: opt.errs,
 		}
 
 		err := bindValue(p, subValue, subOpt)
@@ -171,11 +183,9 @@ func bindArray(p *Properties, v reflect.Value, opt bindOption) error {
 
 func bindSlice(p *Properties, v reflect.Value, opt bindOption) error {
 
-	if opt.hasDef {
-		if opt.def == "" {
-			return nil
-		}
-		return fmt.Errorf("%s slice 类型不能指定非空默认值", opt.path)
+	p, err := expand(p, opt)
+	if p == nil || err != nil {
+		return err
 	}
 
 	et := opt.typ.Elem()
@@ -185,7 +195,12 @@ func bindSlice(p *Properties, v reflect.Value, opt bindOption) error {
 
 		subKey := fmt.Sprintf("%s[%d]", opt.key, i)
 		subPath := fmt.Sprintf("%s[%d]", opt.path, i)
-		subOpt := bindOption{typ: et, key: subKey, path: subPath}
+		subOpt := bindOption{typ: et, key: subKey, path: subPath, errs: opt.errs}
+
+		// 元素的所有字段都有默认值时只能通过属性是否存在判断 slice 是否结束。
+		if exact, children := lookupKey(p, subKey); !exact && !children {
+			break
+		}
 
 		e := reflect.New(et).Elem()
 		err := bindValue(p, e, subOpt)
@@ -205,43 +220,50 @@ func bindSlice(p *Properties, v reflect.Value, opt bindOption) error {
 
 func bindMap(p *Properties, v reflect.Value, opt bindOption) error {
 
-	if opt.hasDef {
-		if opt.def == "" {
-			return nil
-		}
-		return fmt.Errorf("%s map 类型不能指定非空默认值", opt.path)
+	p, err := expand(p, opt)
+	if p == nil || err != nil {
+		return err
 	}
 
 	opt.key = strings.TrimPrefix(opt.key, RootKey+".")
 
 	et := opt.typ.Elem()
-	keys := make(map[string]struct{})
-	for _, key := range p.Keys() {
 
-		subKey := key
-		if opt.key != RootKey {
-			if !strings.HasPrefix(key, opt.key+".") {
+	// 结构体、map 以及 list 类型的元素使用直接子属性的属性名作为 map 的 key ，
+	// 其他类型的元素使用叶子属性的相对路径作为 map 的 key 。
+	direct := false
+	switch et.Kind() {
+	case reflect.Struct:
+		direct = p.converter(et) == nil
+	case reflect.Map, reflect.Slice, reflect.Array:
+		direct = true
+	}
+
+	var keys []string
+	n := p.tree()
+	if opt.key != RootKey {
+		n = p.lookup(opt.key)
+	}
+	if n != nil {
+		for _, c := range n.sortedChildren() {
+			if c.empty() {
 				continue
 			}
-			subKey = strings.TrimPrefix(key, opt.key+".")
-		}
-
-		if et.Kind() == reflect.Struct {
-			if fn, _ := converters[opt.typ]; fn == nil {
-				subKey = strings.Split(subKey, ".")[0]
+			if direct {
+				keys = append(keys, c.name)
+				continue
 			}
-		}
-
-		if _, ok := keys[subKey]; !ok {
-			keys[subKey] = struct{}{}
+			c.walk(c.name, func(key string, _ *node) {
+				keys = append(keys, key)
+			})
 		}
 	}
 
 	m := reflect.MakeMap(opt.typ)
-	for key := range keys {
+	for _, key := range keys {
 		e := reflect.New(et).Elem()
 		subKey := fmt.Sprintf("%s.%s", opt.key, key)
-		subOpt := bindOption{typ: et, key: subKey, path: opt.path}
+		subOpt := bindOption{typ: et, key: subKey, path: opt.path, errs: opt.errs}
 		err := bindValue(p, e, subOpt)
 		if err != nil {
 			return err
@@ -254,8 +276,9 @@ func bindMap(p *Properties, v reflect.Value, opt bindOption) error {
 
 func bindStruct(p *Properties, v reflect.Value, opt bindOption) error {
 
-	if opt.hasDef && opt.def != "" {
-		return fmt.Errorf("%s struct 类型不能指定非空默认值", opt.path)
+	p, err := expand(p, opt)
+	if p == nil || err != nil {
+		return err
 	}
 
 	for i := 0; i < opt.typ.NumField(); i++ {
@@ -270,9 +293,11 @@ func bindStruct(p *Properties, v reflect.Value, opt bindOption) error {
 		}
 
 		subOpt := bindOption{
-			typ:  ft.Type,
-			key:  opt.key,
-			path: opt.path + "." + ft.Name,
+			typ:      ft.Type,
+			key:      opt.key,
+			path:     opt.path + "." + ft.Name,
+			validate: ft.Tag.Get("validate"),
+			errs:     opt.errs,
 		}
 
 		if tag, ok := ft.Tag.Lookup("value"); ok {
@@ -294,6 +319,82 @@ func bindStruct(p *Properties, v reflect.Value, opt bindOption) error {
 	return nil
 }
 
+// lookupKey 返回属性列表中是否存在 key 对应的属性，以及是否存在 key.sub 或者
+// key[i] 形式的子属性。
+func lookupKey(p *Properties, key string) (exact bool, children bool) {
+	if n := p.lookup(strings.TrimPrefix(key, RootKey+".")); n != nil {
+		return n.hasValue, n.hasChildren()
+	}
+	return false, false
+}
+
+// expand 返回绑定 array 、slice 、map 以及 struct 使用的属性列表。当属性列表中
+// 存在 opt.key 的子属性时直接返回 p ，否则将默认值展开成子属性后保存到 p 的副本中
+// 返回，array 和 slice 还可以使用逗号分隔的属性值。默认值可以是 JSON 字面量，也
+// 可以是逗号分隔的列表 (array 、slice) 或者 k:v 对 (map 、struct) 。array 、
+// slice 以及 map 展开后没有子属性时返回 nil ，表示保持零值。
+func expand(p *Properties, opt bindOption) (*Properties, error) {
+
+	key := strings.TrimPrefix(opt.key, RootKey+".")
+	if key == RootKey {
+		return p, nil
+	}
+
+	exact, children := lookupKey(p, key)
+	if children {
+		return p, nil
+	}
+
+	var (
+		s   string
+		err error
+	)
+
+	isList := opt.typ.Kind() == reflect.Slice || opt.typ.Kind() == reflect.Array
+	switch {
+	case exact && isList:
+		s, err = resolve(p, bindOption{key: key})
+	case opt.hasDef:
+		s, err = resolveString(p, opt.def)
+	default:
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s = strings.TrimSpace(s); s == "" {
+		if opt.typ.Kind() == reflect.Struct {
+			return p, nil // 结构体的字段可能有自己的默认值
+		}
+		return nil, nil
+	}
+
+	q := p.Snapshot()
+	if s[0] == '[' || s[0] == '{' {
+		var v interface{}
+		if err = json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("%s 默认值 %s 不是有效的 JSON: %w", opt.path, s, err)
+		}
+		q.Set(key, v)
+		return q, nil
+	}
+
+	for i, e := range strings.Split(s, ",") {
+		e = strings.TrimSpace(e)
+		if isList {
+			q.put(fmt.Sprintf("%s[%d]", key, i), e)
+			continue
+		}
+		kv := strings.SplitN(e, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%s 默认值 %s 不是有效的 k:v 对", opt.path, s)
+		}
+		q.put(key+"."+strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+	return q, nil
+}
+
 // validTag 返回是否为 ${key:=def} 格式的字符串。
 func validTag(tag string) bool {
 	return strings.HasPrefix(tag, "${") && strings.HasSuffix(tag, "}")
@@ -362,16 +463,39 @@ func resolveString(p *Properties, s string) (string, error) {
 	return s[:start] + s1 + s2, nil
 }
 
-// resolve 解析 ${key:=def} 字符串，返回 key 对应的属性值，如果没有找到则返回
-// def 值，如果 def 存在引用则递归解析直到获取最终的属性值。
+// resolve 解析 ${key:=def} 字符串，返回 key 对应的属性值，如果没有找到则尝试
+// 使用函数形式的解析器，仍然没有找到则返回 def 值，如果 def 存在引用则递归解析直
+// 到获取最终的属性值。
 func resolve(p *Properties, opt bindOption) (string, error) {
-	val := p.Get(opt.key)
-	if val == nil {
-		if opt.hasDef {
-			val = opt.def
-		} else {
-			return "", fmt.Errorf("property %q %w", opt.key, ErrNotExist)
+	key := strings.TrimPrefix(opt.key, RootKey+".")
+	val, ok, err := p.get(key)
+	if err != nil {
+		return "", fmt.Errorf("property %q decrypt error: %w", key, err)
+	}
+	if ok {
+		if IsEncrypted(p.raw(key)) {
+			return val, nil // 解密后的属性值不再解析其中的引用
+		}
+		if p.base != nil {
+			return resolveString(p.base, val) // 子树的属性值按照完整的属性名书写
 		}
+		return resolveString(p, val)
+	}
+	if r, arg := findResolver(key); r != nil {
+		arg, err := resolveString(p, arg) // 参数中可以引用其他属性
+		if err != nil {
+			return "", err
+		}
+		s, err := r(arg)
+		if err == nil {
+			return s, nil
+		}
+		if !errors.Is(err, ErrNotExist) {
+			return "", fmt.Errorf("resolve %q error: %w", key, err)
+		}
+	}
+	if opt.hasDef {
+		return resolveString(p, opt.def)
 	}
-	return resolveString(p, val.(string))
+	return "", fmt.Errorf("property %q %w", opt.key, ErrNotExist)
 }
@@ -23,21 +23,75 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/go-spring/spring-core/log"
 	"github.com/go-spring/spring-stl/cast"
 )
 
 const RootKey = "$"
 
-// Properties 提供创建和读取属性列表的方法。它使用扁平的 map[string]string 结
-// 构存储数据，属性的 key 可以是 a.b.c 或者 a[0].b 两种形式，a.b.c 表示从 map
-// 结构中获取属性值，a[0].b 表示从切片结构中获取属性值，并且 key 是大小写敏感的。
-type Properties struct{ m map[string]string }
+// Properties 提供创建和读取属性列表的方法。它使用树形结构存储数据，属性的 key
+// 可以是 a.b.c 或者 a[0].b 两种形式，a.b.c 表示从 map 结构中获取属性值，a[0].b
+// 表示从切片结构中获取属性值，查找属性的时间只与 key 的层级有关。key 是大小写敏
+// 感的，并且按照设置时的写法保存。绑定结构体时字段声明的属性名采用宽松匹配，
+// base-path 、basePath 以及 base_path 都可以匹配字段，同时存在多种写法时使用最
+// 后设置的写法，map 的 key 则保持原样。覆盖已有属性时 map 按照 key 逐个合并，list 则整
+// 体替换，因此较短的 list 不会残留旧的元素，可以通过 Replace 选项整体替换 map 。
+// Properties 可以被并发地读取和修改，修改时采用写时复制的方式发布新的属性树，读取
+// 时不需要加锁，Snapshot 方法可以获得某一时刻的属性视图。
+type Properties struct {
+	mu   sync.Mutex   // 串行化属性的修改
+	root atomic.Value // 当前发布的属性树，类型是 *node
+	c    *Catalog     // 属性元数据
+	u    *sync.Map    // 被读取过的属性
+	pre  string       // Sub 返回的属性列表在原属性列表中的前缀，用于记录读取过的属性
+	base *Properties  // Sub 返回的属性列表所属的完整属性列表的快照，用于解析属性值中的引用
+	cv   atomic.Value // 只对当前属性列表有效的类型转换器，类型是 map[reflect.Type]interface{}
+}
 
 // New 返回一个空的属性列表。
 func New() *Properties {
-	return &Properties{m: make(map[string]string)}
+	p := &Properties{
+		c: NewCatalog(),
+		u: new(sync.Map),
+	}
+	p.root.Store(&node{})
+	p.c.converter = p.converter
+	return p
+}
+
+// tree 返回当前发布的属性树，属性树是只读的。
+func (p *Properties) tree() *node {
+	return p.root.Load().(*node)
+}
+
+// update 使用 fn 返回的新属性树替换当前的属性树，fn 不能修改旧的属性树。
+func (p *Properties) update(fn func(root *node) *node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.root.Store(fn(p.tree()))
+}
+
+// Snapshot 返回当前属性列表的快照，快照不会受到之后的修改的影响，因此一次请求中
+// 多次读取属性时可以使用快照获得一致的视图。快照与当前属性列表共享属性元数据、读
+// 取记录以及类型转换器，创建快照的开销与属性的数量无关。
+func (p *Properties) Snapshot() *Properties {
+	q := &Properties{c: p.c, u: p.u, pre: p.pre, base: p.base}
+	q.root.Store(p.tree())
+	if cv := p.cv.Load(); cv != nil {
+		q.cv.Store(cv)
+	}
+	return q
+}
+
+// Catalog 返回通过 Bind 方法绑定过的所有属性的元数据。
+func (p *Properties) Catalog() *Catalog {
+	return p.c
 }
 
 // Map 返回一个由 map 创建的属性列表。
@@ -59,16 +113,55 @@ func Load(file string) (*Properties, error) {
 }
 
 // Load 从属性文件加载属性列表，file 可以是绝对路径，也可以是相对路径。该方法会覆盖
-// 已有的属性值。
+// 已有的属性值，并且将 file 记录为属性值的来源。
 func (p *Properties) Load(file string) error {
 	b, err := ioutil.ReadFile(file)
 	if err != nil {
 		return err
 	}
-	return p.Read(b, filepath.Ext(file))
+	return p.read(b, filepath.Ext(file), file)
 }
 
-// Read 返回一个由 []byte 创建的属性列表，ext 是文件扩展名，如 .yaml、.toml 等。
+// LoadDocuments 从属性文件加载属性列表，文件包含多个文档时每个文档返回一个属性
+// 列表，比如 YAML 中使用 --- 分隔的多个文档，其他情况下只返回一个属性列表。
+func LoadDocuments(file string) ([]*Properties, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ReadDocuments(b, filepath.Ext(file), Source(file))
+}
+
+// ReadDocuments 从 []byte 加载属性列表，包含多个文档时每个文档返回一个属性列表，
+// ext 是文件扩展名，为空时根据内容推断格式。可以通过 Source 选项设置属性值的来源。
+func ReadDocuments(b []byte, ext string, opts ...SetOption) ([]*Properties, error) {
+	arg := setArg{}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+	if ext == "" {
+		ext = detect(b)
+	}
+	docs := [][]byte{b}
+	if s, ok := splitters[ext]; ok {
+		var err error
+		if docs, err = s(b); err != nil {
+			return nil, err
+		}
+	}
+	var ret []*Properties
+	for _, doc := range docs {
+		p := New()
+		if err := p.read(doc, ext, arg.source); err != nil {
+			return nil, err
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// Read 返回一个由 []byte 创建的属性列表，ext 是文件扩展名，如 .yaml、.toml 等，
+// ext 为空时根据内容推断格式。
 func Read(b []byte, ext string) (*Properties, error) {
 	p := New()
 	if err := p.Read(b, ext); err != nil {
@@ -80,6 +173,14 @@ func Read(b []byte, ext string) (*Properties, error) {
 // Read 从 []byte 加载属性列表，ext 是文件扩展名，如 .yaml、.toml 等。该方法会覆
 // 盖已有的属性值。
 func (p *Properties) Read(b []byte, ext string) error {
+	return p.read(b, ext, "")
+}
+
+func (p *Properties) read(b []byte, ext string, source string) error {
+
+	if ext == "" {
+		ext = detect(b)
+	}
 
 	r, ok := readers[ext]
 	if !ok {
@@ -91,21 +192,46 @@ func (p *Properties) Read(b []byte, ext string) error {
 		return err
 	}
 
+	arg := setArg{source: source}
+	if l, ok := locators[ext]; ok {
+		if arg.lines, err = l(b); err != nil {
+			return err
+		}
+	}
+
+	// 先将整个文件转换成属性树再合并，这样文件中的 list 会整体替换已有的 list 。
+	src := &node{}
 	for k, v := range m {
-		p.Set(k, v)
+		n := src.find(parseKey(k), true)
+		*n = *n.merge(build(k, v, arg))
 	}
+
+	p.update(func(root *node) *node {
+		return root.merge(src)
+	})
 	return nil
 }
 
-// Keys 返回所有属性 key 的列表。
+// Keys 返回所有属性 key 的列表，按照字典序排列。
 func (p *Properties) Keys() []string {
-	keys := make([]string, 0, len(p.m))
-	for k := range p.m {
-		keys = append(keys, k)
-	}
+	var keys []string
+	p.tree().walk("", func(key string, _ *node) {
+		keys = append(keys, key)
+	})
 	return keys
 }
 
+// Used 返回 key 对应的属性值是否被读取过，Bind、Resolve 以及 Get 方法都会记录
+// 读取过的属性，可以据此找出没有被使用的属性。
+func (p *Properties) Used(key string) bool {
+	return p.used(p.pre + strings.TrimPrefix(key, RootKey+"."))
+}
+
+func (p *Properties) used(key string) bool {
+	_, ok := p.u.Load(key)
+	return ok
+}
+
 type getArg struct {
 	def interface{}
 }
@@ -126,7 +252,10 @@ func Def(v interface{}) GetOption {
 func (p *Properties) Get(key string, opts ...GetOption) interface{} {
 
 	key = strings.TrimPrefix(key, RootKey+".")
-	if val, ok := p.m[key]; ok {
+	if val, ok, err := p.get(key); ok {
+		if err != nil {
+			log.Errorf("property %q decrypt error: %v", key, err)
+		}
 		return val
 	}
 
@@ -141,38 +270,221 @@ func (p *Properties) Get(key string, opts ...GetOption) interface{} {
 	return nil
 }
 
+// get 返回 key 对应的属性值，ENC(...) 形式的属性值会被解密，解密失败时返回密文
+// 和错误。
+func (p *Properties) get(key string) (string, bool, error) {
+	var buf [maxKeyDepth]segment
+	n := p.tree().find(appendKey(buf[:0], key), false)
+	if n == nil || !n.hasValue {
+		return "", false, nil
+	}
+	val := n.value
+	if k := p.pre + key; !p.used(k) {
+		p.u.Store(k, struct{}{})
+	}
+	s, err := decrypt(val)
+	if err != nil {
+		return val, true, err
+	}
+	return s, true, nil
+}
+
+// lookup 返回 key 对应的结点，找不到时返回 nil 。
+func (p *Properties) lookup(key string) *node {
+	var buf [maxKeyDepth]segment
+	return p.tree().find(appendKey(buf[:0], key), false)
+}
+
+// fieldKey 返回结构体字段声明的属性名 key 在属性树中的实际写法，prefix 是字段
+// 所属结构体的属性名，它已经是实际的写法。key 采用宽松匹配，找不到时返回 key 本
+// 身。
+func (p *Properties) fieldKey(prefix string, key string) string {
+	n := p.tree()
+	if prefix = strings.TrimPrefix(prefix, RootKey); prefix != "" {
+		if n = p.lookup(strings.TrimPrefix(prefix, ".")); n == nil {
+			return key
+		}
+	}
+	_, path := n.relaxed(parseKey(key))
+	if path == nil {
+		return key
+	}
+	key = ""
+	for _, seg := range path {
+		key = childKey(key, seg)
+	}
+	return key
+}
+
+// canonicalKey 返回 key 的规范形式，驼峰和下划线的写法都会转换成短横线的写法，比
+// 如 basePath 和 base_path 都会转换成 base-path ，其他字符保持不变。规范形式只
+// 用于宽松匹配结构体字段声明的属性名，属性树按照属性名的实际写法保存。
+func canonicalKey(key string) string {
+	if isCanonical(key) {
+		return key
+	}
+	var sb strings.Builder
+	sb.Grow(len(key) + 4)
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c == '_':
+			sb.WriteByte('-')
+		case c >= 'A' && c <= 'Z' && i > 0 && isLowerOrDigit(key[i-1]):
+			sb.WriteByte('-')
+			sb.WriteByte(c - 'A' + 'a')
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// isCanonical 返回 key 是否已经是规范形式，大多数 key 都是规范形式，这样可以避
+// 免不必要的内存分配。
+func isCanonical(key string) bool {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '_' || (c >= 'A' && c <= 'Z' && i > 0 && isLowerOrDigit(key[i-1])) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLowerOrDigit(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+// put 保存属性值并且追加属性值的来源。
+func (p *Properties) put(key string, val string, origins ...Origin) {
+	src := &node{value: val, hasValue: true, origins: origins, seq: nextSeq()}
+	p.update(func(root *node) *node {
+		return root.with(parseKey(key), func(n *node) *node {
+			return n.merge(src)
+		})
+	})
+}
+
+// raw 返回 key 对应的原始属性值，不标记读取也不解密。
+func (p *Properties) raw(key string) string {
+	if n := p.lookup(key); n != nil {
+		return n.value
+	}
+	return ""
+}
+
+// Reload 使用 q 中的属性整体替换当前的属性，替换过程是原子的，并发的读取要么看到
+// 旧的属性要么看到新的属性。返回值是新增、删除以及值发生变化的属性名，按字典序排
+// 列。属性值的来源随之替换，是否被读取过的记录保持不变。
+func (p *Properties) Reload(q *Properties) []string {
+
+	root := q.tree()
+	m := make(map[string]string)
+	root.walk("", func(key string, n *node) {
+		m[key] = n.value
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var changed []string
+	p.tree().walk("", func(key string, n *node) {
+		if v, ok := m[key]; !ok || v != n.value {
+			changed = append(changed, key)
+		}
+		delete(m, key)
+	})
+	for k := range m {
+		changed = append(changed, k)
+	}
+	sort.Strings(changed)
+
+	p.root.Store(root)
+	return changed
+}
+
+// Dump 返回所有属性的副本，可以用于输出属性列表，加密的属性值保持 ENC(...) 形式。
+func (p *Properties) Dump() map[string]string {
+	m := make(map[string]string)
+	p.tree().walk("", func(key string, n *node) {
+		m[key] = n.value
+	})
+	return m
+}
+
 // Set 设置 key 对应的属性值，如果 key 对应的属性值已经存在则 Set 方法会覆盖旧
 // 值。Set 方法除了支持 string 类型的属性值，还支持 int、uint、bool 等其他基础
 // 数据类型的属性值。特殊情况下，Set 方法也支持 slice 、map 与基础数据类型组合构
 // 成的属性值，其处理方式是将组合结构层层展开，可以将组合结构看成一棵树，那么叶子结
-// 点的路径就是属性的 key，叶子结点的值就是属性的值。
-func (p *Properties) Set(key string, val interface{}) {
-	v := reflect.ValueOf(val)
-	switch v.Kind() {
-	case reflect.Map:
-		for _, k := range v.MapKeys() {
-			mapValue := v.MapIndex(k).Interface()
-			mapKey := cast.ToString(k.Interface())
-			p.Set(key+"."+mapKey, mapValue)
-		}
-	case reflect.Array, reflect.Slice:
-		for i := 0; i < v.Len(); i++ {
-			subKey := fmt.Sprintf("%s[%d]", key, i)
-			subValue := v.Index(i).Interface()
-			p.Set(subKey, subValue)
-		}
-	default:
-		p.m[key] = cast.ToString(val)
+// 点的路径就是属性的 key，叶子结点的值就是属性的值。slice 会整体替换已有的 list
+// ，map 默认按照 key 逐个合并，使用 Replace 选项时整体替换。可以通过 Source 选
+// 项设置属性值的来源。
+func (p *Properties) Set(key string, val interface{}, opts ...SetOption) {
+	arg := setArg{}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+	p.set(key, val, arg)
+}
+
+func (p *Properties) set(key string, val interface{}, arg setArg) {
+	src := build(key, val, arg)
+	p.update(func(root *node) *node {
+		return root.with(parseKey(key), func(n *node) *node {
+			if arg.replace {
+				r := *src
+				r.name = n.name
+				return &r
+			}
+			return n.merge(src)
+		})
+	})
+}
+
+// Batch 收集一批属性然后一次性发布，用于加载环境变量、命令行参数等大量零散的属
+// 性。逐个调用 Set 时每次都要复制并且发布从根结点到属性结点的路径，Batch 则和读
+// 取属性文件一样，先在私有的属性树中原地合并，最后通过 Properties.Apply 只合并和
+// 发布一次。Batch 不是并发安全的。
+type Batch struct {
+	root *node
+}
+
+// NewBatch 返回一个空的 Batch 。
+func NewBatch() *Batch {
+	return &Batch{root: &node{}}
+}
+
+// Set 设置 key 对应的属性值，后设置的属性值覆盖先设置的属性值，属性值的类型以及
+// Source 选项与 Properties.Set 相同。Batch 中的 map 发布时与已有的属性按照 key
+// 逐个合并，因此不支持 Replace 选项。
+func (b *Batch) Set(key string, val interface{}, opts ...SetOption) {
+	arg := setArg{}
+	for _, opt := range opts {
+		opt(&arg)
 	}
+	n := b.root.find(parseKey(key), true)
+	*n = *n.merge(build(key, val, arg))
+}
+
+// Apply 将 b 中的属性合并到属性列表中，只发布一次新的属性树。合并之后 b 被清空，
+// 可以继续使用。
+func (p *Properties) Apply(b *Batch) {
+	src := b.root
+	b.root = &node{}
+	p.update(func(root *node) *node {
+		return root.merge(src)
+	})
 }
 
 // Resolve 解析字符串中包含的所有属性引用即 ${key:=def} 的内容，并且支持递归引用。
 func (p *Properties) Resolve(s string) (string, error) {
-	return resolveString(p, s)
+	return resolveString(p.Snapshot(), s)
 }
 
 type bindArg struct {
-	tag string
+	tag      string
+	fileLine string
 }
 
 type BindOption func(arg *bindArg)
@@ -191,16 +503,27 @@ func Tag(tag string) BindOption {
 	}
 }
 
+// FileLine 设置发起属性绑定的代码位置，用于记录属性元数据，默认是 Bind 方法的
+// 调用位置。
+func FileLine(fileLine string) BindOption {
+	return func(arg *bindArg) {
+		arg.fileLine = fileLine
+	}
+}
+
 // Bind 将 key 对应的属性值绑定到某个数据类型的实例上。i 必须是一个指针，只有这
 // 样才能将修改传递出去。Bind 方法使用 tag 字符串对数据实例进行属性绑定，其语法
 // 为 value:"${a:=b}"，其中 value 表示属性绑定，${} 表示属性引用，a 表示属性
 // 的名称，:=b 表示为属性设置默认值。而且 tag 字符串还支持在默认值中进行嵌套引用
 // ，即 ${a:=${b}}。当然，还有两点需要特别说明：
-// 一是对 array、slice、map、struct 这些复合类型不能设置非空默认值，因为如果
-// 默认值太长会影响阅读体验，而且解析起来也并不容易；
+// 一是 array、slice、map、struct 这些复合类型的默认值可以是 JSON 字面量，也可
+// 以是逗号分隔的列表 ${hosts:=a,b,c} 或者 k:v 对 ${labels:=env:dev,zone:cn}，
+// 并且 array 和 slice 还可以绑定逗号分隔的属性值，如 topics=a,b ；
 // 二是可以省略属性名而只有默认值，即 ${:=b}，原因是某些情况下属性名可能没想好或
 // 者不太重要，比如，得益于字符串差值的实现，这种语法可以用于动态生成新的属性值，
 // 也有人认为这是一种对 Golang 缺少默认值语法的补充，Bug is Feature。
+// 另外，结构体字段可以通过 validate 标签设置校验规则，比如 validate:"min=1"，
+// Bind 方法会检查所有字段并且返回包含全部违规信息的 *ValidateError 。
 func (p *Properties) Bind(i interface{}, opts ...BindOption) error {
 
 	var v reflect.Value
@@ -228,5 +551,21 @@ func (p *Properties) Bind(i interface{}, opts ...BindOption) error {
 		s = t.String()
 	}
 
-	return bind(p, v, arg.tag, bindOption{typ: t, path: s})
+	if arg.fileLine == "" {
+		_, file, line, _ := runtime.Caller(1)
+		arg.fileLine = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	// 属性元数据只是辅助信息，绑定的错误由 bind 函数报告。
+	_ = p.c.Scan(t, arg.tag, arg.fileLine)
+
+	errs := &ValidateError{}
+	err := bind(p.Snapshot(), v, arg.tag, bindOption{typ: t, path: s, errs: errs})
+	if err != nil {
+		return err
+	}
+	if len(errs.Violations) > 0 {
+		return errs
+	}
+	return nil
 }
@@ -17,14 +17,27 @@
 package conf_test
 
 import (
+	"bytes"
 	"container/list"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/conf/hcl"
 	"github.com/go-spring/spring-core/log"
 	"github.com/go-spring/spring-stl/assert"
 	"github.com/go-spring/spring-stl/cast"
@@ -945,3 +958,1321 @@ func TestInterpolate(t *testing.T) {
 	str, _ = p.Resolve("my name is ${name} my name is ${name}")
 	assert.Equal(t, str, "my name is Jim my name is Jim")
 }
+
+type CatalogServerConfig struct {
+	Port    int           `value:"${port:=8080}"`
+	Hosts   []string      `value:"${hosts:=}"`
+	Timeout time.Duration `value:"${timeout:=1s}"`
+}
+
+type CatalogConfig struct {
+	Name    string                         `value:"${app.name}"`
+	Server  CatalogServerConfig            `value:"${web.server}"`
+	Labels  map[string]string              `value:"${app.labels:=}"`
+	Servers map[string]CatalogServerConfig `value:"${servers:=}"`
+}
+
+func TestCatalog(t *testing.T) {
+
+	p := conf.New()
+	p.Set("app.name", "test")
+	var c CatalogConfig
+	err := p.Bind(&c, conf.FileLine("main.go:12"))
+	assert.Nil(t, err)
+
+	var keys []string
+	metas := make(map[string]conf.Meta)
+	for _, m := range p.Catalog().Metas() {
+		keys = append(keys, m.Key)
+		metas[m.Key] = m
+	}
+	assert.Equal(t, keys, []string{
+		"app.labels.*",
+		"app.name",
+		"servers.*.hosts",
+		"servers.*.port",
+		"servers.*.timeout",
+		"web.server.hosts",
+		"web.server.port",
+		"web.server.timeout",
+	})
+
+	assert.Equal(t, metas["web.server.port"], conf.Meta{
+		Key:        "web.server.port",
+		Type:       "int",
+		Default:    "8080",
+		HasDefault: true,
+		Struct:     "conf_test.CatalogServerConfig",
+		Field:      "CatalogConfig.Server.Port",
+		FileLine:   "main.go:12",
+	})
+	assert.Equal(t, metas["app.name"].HasDefault, false)
+	assert.Equal(t, metas["app.labels.*"].Type, "string")
+	assert.Equal(t, metas["web.server.timeout"].Type, "time.Duration")
+
+	m, ok := p.Catalog().Lookup("web.server.port")
+	assert.True(t, ok)
+	assert.Equal(t, m.Type, "int")
+	m, ok = p.Catalog().Lookup("app.labels.env")
+	assert.True(t, ok)
+	assert.Equal(t, m.Key, "app.labels.*")
+	_, ok = p.Catalog().Lookup("web.server.prot")
+	assert.False(t, ok)
+
+	b, err := p.Catalog().JSON()
+	assert.Nil(t, err)
+	var r []map[string]interface{}
+	err = json.Unmarshal(b, &r)
+	assert.Nil(t, err)
+	assert.Equal(t, len(r), len(keys))
+
+	buf := bytes.NewBuffer(nil)
+	p.Catalog().Usage(buf)
+	assert.True(t, strings.Contains(buf.String(), "  --web.server.port int (default \"8080\")\n"))
+}
+
+func TestProperties_Used(t *testing.T) {
+
+	p := conf.New()
+	p.Set("app.name", "test")
+	p.Set("web.server.port", 9090)
+	p.Set("web.server.prot", 9091)
+	p.Set("app.version", "1.0")
+	p.Set("app.home", "/root")
+
+	var c CatalogConfig
+	err := p.Bind(&c)
+	assert.Nil(t, err)
+
+	s, err := p.Resolve("${app.home}/bin")
+	assert.Nil(t, err)
+	assert.Equal(t, s, "/root/bin")
+
+	var unused []string
+	for _, k := range p.Keys() {
+		if !p.Used(k) {
+			unused = append(unused, k)
+		}
+	}
+	sort.Strings(unused)
+	assert.Equal(t, unused, []string{"app.version", "web.server.prot"})
+
+	catalog := p.Catalog()
+	assert.True(t, catalog.Unknown("web.server.prot"))
+	assert.False(t, catalog.Unknown("web.server.port"))
+	assert.False(t, catalog.Unknown("web.server.hosts[0]"))
+	assert.True(t, catalog.Unknown("servers.a.prot"))
+	assert.False(t, catalog.Unknown("servers.a.port"))
+	assert.False(t, catalog.Unknown("servers.a.hosts[1]"))
+	assert.False(t, catalog.Unknown("app.version"))
+	assert.False(t, catalog.Unknown("web.client.port"))
+}
+
+func TestProperties_Origins(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "conf")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	propFile := filepath.Join(dir, "app.properties")
+	err = ioutil.WriteFile(propFile, []byte("# comment\nweb.port=8080\nweb.host = a\\\n  b\nweb.name:test\n"), 0644)
+	assert.Nil(t, err)
+
+	tomlFile := filepath.Join(dir, "app.toml")
+	err = ioutil.WriteFile(tomlFile, []byte("[web]\nport = 9090\n\n[[web.servers]]\nhost = \"a\"\n"), 0644)
+	assert.Nil(t, err)
+
+	p := conf.New()
+	p.Set("web.timeout", "1s")
+	err = p.Load(propFile)
+	assert.Nil(t, err)
+	err = p.Load(tomlFile)
+	assert.Nil(t, err)
+
+	q := conf.New()
+	q.Set("web.port", 7070, conf.Source("env:GS_WEB_PORT"))
+	p.Merge(q)
+
+	assert.Equal(t, p.Get("web.port"), "7070")
+	assert.Equal(t, p.Origins("web.port"), []conf.Origin{
+		{Source: propFile, Line: 2, Value: "8080"},
+		{Source: tomlFile, Line: 2, Value: "9090"},
+		{Source: "env:GS_WEB_PORT", Value: "7070"},
+	})
+	assert.Equal(t, p.Origins("web.host"), []conf.Origin{{Source: propFile, Line: 3, Value: "ab"}})
+	assert.Equal(t, p.Origins("web.name")[0].String(), propFile+":5")
+	assert.Equal(t, p.Origins("web.servers[0].host")[0].String(), tomlFile+":5")
+	assert.Equal(t, p.Origins("web.timeout")[0].String(), "<code>")
+	assert.Equal(t, len(p.Origins("web.unknown")), 0)
+
+	// 反复修改同一个属性时只保留最近的 16 个来源
+	r := conf.New()
+	for i := 0; i < 20; i++ {
+		r.Set("counter", i)
+	}
+	origins := r.Origins("counter")
+	assert.Equal(t, len(origins), 16)
+	assert.Equal(t, origins[0].Value, "4")
+	assert.Equal(t, origins[15].Value, "19")
+}
+
+func TestResolver(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "conf")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "password")
+	err = ioutil.WriteFile(file, []byte("  123456\n"), 0644)
+	assert.Nil(t, err)
+
+	_ = os.Setenv("CONF_TEST_HOME", "/home/conf")
+	defer os.Unsetenv("CONF_TEST_HOME")
+
+	p := conf.New()
+	p.Set("secret.dir", dir)
+
+	t.Run("env", func(t *testing.T) {
+		s, err := p.Resolve("${env:CONF_TEST_HOME}/bin")
+		assert.Nil(t, err)
+		assert.Equal(t, s, "/home/conf/bin")
+		s, err = p.Resolve("${env:CONF_TEST_NOT_EXIST:=${secret.dir}}")
+		assert.Nil(t, err)
+		assert.Equal(t, s, dir)
+		_, err = p.Resolve("${env:CONF_TEST_NOT_EXIST}")
+		assert.Error(t, err, "property \"env:CONF_TEST_NOT_EXIST\" not exist")
+	})
+
+	t.Run("file", func(t *testing.T) {
+		s, err := p.Resolve("${file:" + file + "}")
+		assert.Nil(t, err)
+		assert.Equal(t, s, "123456")
+		s, err = p.Resolve("${file:${secret.dir}/password}")
+		assert.Nil(t, err)
+		assert.Equal(t, s, "123456")
+		s, err = p.Resolve("${file:${secret.dir}/not_exist:=abc}")
+		assert.Nil(t, err)
+		assert.Equal(t, s, "abc")
+	})
+
+	t.Run("base64", func(t *testing.T) {
+		s, err := p.Resolve("${base64:aGVsbG8=}")
+		assert.Nil(t, err)
+		assert.Equal(t, s, "hello")
+		_, err = p.Resolve("${base64:!!}")
+		assert.Error(t, err, "resolve \"base64:!!\" error")
+	})
+
+	t.Run("random", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			s, err := p.Resolve("${random.int(1,3)}")
+			assert.Nil(t, err)
+			assert.True(t, s == "1" || s == "2")
+		}
+		_, err := p.Resolve("${random.int(3,1)}")
+		assert.Error(t, err, "random.int\\(3,1\\) 参数错误")
+
+		var v struct {
+			ID   string `value:"${random.uuid}"`
+			Port int    `value:"${random.int(1024,65536)}"`
+		}
+		err = p.Bind(&v)
+		assert.Nil(t, err)
+		assert.Matches(t, v.ID, "^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+		assert.True(t, v.Port >= 1024 && v.Port < 65536)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		conf.NewResolver("upper", func(arg string) (string, error) {
+			return strings.ToUpper(arg), nil
+		})
+		s, err := p.Resolve("${upper(abc)}-${upper:def}")
+		assert.Nil(t, err)
+		assert.Equal(t, s, "ABC-DEF")
+	})
+}
+
+type ValidateServerConfig struct {
+	Port    int           `value:"${port:=8080}" validate:"min=1,max=65535"`
+	URL     string        `value:"${url}" validate:"required,regex=^https?://"`
+	Mode    string        `value:"${mode:=debug}" validate:"oneof=debug release"`
+	Timeout time.Duration `value:"${timeout:=1s}" validate:"min=10ms,max=1m"`
+}
+
+type ValidateConfig struct {
+	Servers []ValidateServerConfig `value:"${servers}"`
+	Name    string                 `value:"${name}" validate:"min=2"`
+}
+
+func TestProperties_Validate(t *testing.T) {
+
+	t.Run("success", func(t *testing.T) {
+		p := conf.New()
+		p.Set("name", "app")
+		p.Set("servers[0].url", "http://127.0.0.1")
+		var c ValidateConfig
+		err := p.Bind(&c)
+		assert.Nil(t, err)
+		assert.Equal(t, c.Servers[0].Port, 8080)
+	})
+
+	t.Run("violations", func(t *testing.T) {
+		p := conf.New()
+		p.Set("name", "a")
+		p.Set("servers[0].port", 70000)
+		p.Set("servers[0].url", "ftp://127.0.0.1")
+		p.Set("servers[1].url", "")
+		p.Set("servers[1].mode", "test")
+		p.Set("servers[1].timeout", "1ms")
+		var c ValidateConfig
+		err := p.Bind(&c)
+		var e *conf.ValidateError
+		assert.True(t, errors.As(err, &e))
+		var ss []string
+		for _, v := range e.Violations {
+			ss = append(ss, v.String())
+		}
+		assert.Equal(t, ss, []string{
+			`property "servers[0].port" (ValidateConfig.Servers[0].Port) violates max=65535`,
+			`property "servers[0].url" (ValidateConfig.Servers[0].URL) violates regex=^https?://`,
+			`property "servers[1].url" (ValidateConfig.Servers[1].URL) violates required`,
+			`property "servers[1].url" (ValidateConfig.Servers[1].URL) violates regex=^https?://`,
+			`property "servers[1].mode" (ValidateConfig.Servers[1].Mode) violates oneof=debug release`,
+			`property "servers[1].timeout" (ValidateConfig.Servers[1].Timeout) violates min=10ms`,
+			`property "name" (ValidateConfig.Name) violates min=2`,
+		})
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		p := conf.New()
+		var v struct {
+			Port int `value:"${port:=1}" validate:"min"`
+		}
+		err := p.Bind(&v)
+		assert.Error(t, err, "Port 校验规则 min 语法错误: min 规则缺少参数")
+	})
+}
+
+func TestProperties_BindDefault(t *testing.T) {
+
+	type Server struct {
+		Host string `value:"${host}"`
+		Port int    `value:"${port:=80}"`
+	}
+
+	type Config struct {
+		Hosts   []string          `value:"${hosts:=a, b,c}"`
+		Ports   [2]int            `value:"${ports:=8080,8081}"`
+		Topics  []string          `value:"${amqp.queue.topics}"`
+		Labels  map[string]string `value:"${labels:=env:dev,zone:cn}"`
+		Limits  map[string]int    `value:"${limits:={\"qps\":100,\"conn\":10}}"`
+		Server  Server            `value:"${server:=host:127.0.0.1}"`
+		Servers []Server          `value:"${servers:=[{\"host\":\"a\"},{\"host\":\"b\",\"port\":81}]}"`
+		Empty   []string          `value:"${empty:=}"`
+		Refs    []string          `value:"${refs:=${amqp.queue.topics},c}"`
+	}
+
+	t.Run("default", func(t *testing.T) {
+		p := conf.New()
+		p.Set("amqp.queue.topics", "a,b")
+		var c Config
+		err := p.Bind(&c)
+		assert.Nil(t, err)
+		assert.Equal(t, c.Hosts, []string{"a", "b", "c"})
+		assert.Equal(t, c.Ports, [2]int{8080, 8081})
+		assert.Equal(t, c.Topics, []string{"a", "b"})
+		assert.Equal(t, c.Labels, map[string]string{"env": "dev", "zone": "cn"})
+		assert.Equal(t, c.Limits, map[string]int{"qps": 100, "conn": 10})
+		assert.Equal(t, c.Server, Server{Host: "127.0.0.1", Port: 80})
+		assert.Equal(t, c.Servers, []Server{{Host: "a", Port: 80}, {Host: "b", Port: 81}})
+		assert.True(t, c.Empty == nil)
+		assert.Equal(t, c.Refs, []string{"a", "b", "c"})
+	})
+
+	t.Run("override", func(t *testing.T) {
+		p := conf.New()
+		p.Set("hosts", []string{"x"})
+		p.Set("labels.env", "prod")
+		p.Set("server.host", "10.0.0.1")
+		p.Set("amqp.queue.topics", "a")
+		p.Set("empty[0]", "e")
+		var c Config
+		err := p.Bind(&c)
+		assert.Nil(t, err)
+		assert.Equal(t, c.Hosts, []string{"x"})
+		assert.Equal(t, c.Labels, map[string]string{"env": "prod"})
+		assert.Equal(t, c.Server, Server{Host: "10.0.0.1", Port: 80})
+		assert.Equal(t, c.Empty, []string{"e"})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := conf.New()
+		var v struct {
+			Labels map[string]string `value:"${labels:=a}"`
+		}
+		err := p.Bind(&v)
+		assert.Error(t, err, "Labels 默认值 a 不是有效的 k:v 对")
+	})
+}
+
+type upperString string
+
+func (s *upperString) UnmarshalText(text []byte) error {
+	*s = upperString(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestProperties_Convert(t *testing.T) {
+
+	t.Run("builtin", func(t *testing.T) {
+		p := conf.New()
+		p.Set("ip", "192.168.1.1")
+		p.Set("cidr", "10.0.0.0/8")
+		p.Set("url", "http://localhost:8080/path")
+		p.Set("regex", "^a+$")
+		p.Set("size", "10MB")
+		p.Set("mode", "0644")
+		p.Set("name", "go-spring")
+		p.Set("hosts", "1.1.1.1,2.2.2.2")
+		var s struct {
+			IP    net.IP         `value:"${ip}"`
+			CIDR  *net.IPNet     `value:"${cidr}"`
+			URL   url.URL        `value:"${url}"`
+			Regex *regexp.Regexp `value:"${regex}"`
+			Size  conf.ByteSize  `value:"${size}"`
+			Mode  os.FileMode    `value:"${mode}"`
+			Name  upperString    `value:"${name}"`
+			Hosts []net.IP       `value:"${hosts}"`
+		}
+		err := p.Bind(&s)
+		assert.Nil(t, err)
+		assert.Equal(t, s.IP.String(), "192.168.1.1")
+		assert.Equal(t, s.CIDR.String(), "10.0.0.0/8")
+		assert.Equal(t, s.URL.Host, "localhost:8080")
+		assert.True(t, s.Regex.MatchString("aaa"))
+		assert.Equal(t, s.Size, 10*conf.MB)
+		assert.Equal(t, s.Mode, os.FileMode(0644))
+		assert.Equal(t, s.Name, upperString("GO-SPRING"))
+		assert.Equal(t, len(s.Hosts), 2)
+		assert.Equal(t, s.Hosts[1].String(), "2.2.2.2")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := conf.New()
+		p.Set("ip", "999.1.1.1")
+		var ip net.IP
+		err := p.Bind(&ip, conf.Key("ip"))
+		assert.Error(t, err, "invalid IP address \"999.1.1.1\"")
+	})
+
+	t.Run("local", func(t *testing.T) {
+		p1 := conf.New()
+		p1.Convert(func(s string) (upperString, error) {
+			return upperString("p1:" + s), nil
+		})
+		p1.Set("name", "a")
+		var s1 upperString
+		err := p1.Bind(&s1, conf.Key("name"))
+		assert.Nil(t, err)
+		assert.Equal(t, s1, upperString("p1:a"))
+
+		p2 := conf.New()
+		p2.Set("name", "a")
+		var s2 upperString
+		err = p2.Bind(&s2, conf.Key("name"))
+		assert.Nil(t, err)
+		assert.Equal(t, s2, upperString("A"))
+	})
+}
+
+func TestParseByteSize(t *testing.T) {
+	testcases := []struct {
+		s    string
+		size conf.ByteSize
+		err  bool
+	}{
+		{"1024", 1024, false},
+		{"10B", 10, false},
+		{"10kb", 10 * conf.KB, false},
+		{"10 MB", 10 * conf.MB, false},
+		{"2G", 2 * conf.GB, false},
+		{"1TB", conf.TB, false},
+		{"MB", 0, true},
+		{"10XB", 0, true},
+	}
+	for _, c := range testcases {
+		size, err := conf.ParseByteSize(c.s)
+		assert.Equal(t, err != nil, c.err)
+		assert.Equal(t, size, c.size)
+	}
+}
+
+func TestProperties_Relaxed(t *testing.T) {
+
+	p := conf.New()
+	p.Set("server.base-path", "/a", conf.Source("a.yaml"))
+	p.Set("server.max_conns", 10)
+	p.Set("server.labels.zoneName", "cn")
+	p.Set("server.labels.zone_name", "us")
+	p.Set("server.basePath", "/b", conf.Source("env:GS_SERVER_BASEPATH"))
+
+	// 属性按照设置时的写法保存，Get 需要精确匹配。
+	assert.Equal(t, p.Keys(), []string{
+		"server.base-path",
+		"server.basePath",
+		"server.labels.zoneName",
+		"server.labels.zone_name",
+		"server.max_conns",
+	})
+	assert.Equal(t, p.Get("server.base-path"), "/a")
+	assert.Nil(t, p.Get("server.base_path"))
+	assert.Equal(t, len(p.Origins("server.basePath")), 1)
+
+	// 结构体字段宽松匹配，多种写法时使用最后设置的写法，map 的 key 保持原样。
+	var s struct {
+		BasePath string            `value:"${base-path}"`
+		MaxConns int               `value:"${maxConns}"`
+		Labels   map[string]string `value:"${labels}"`
+	}
+	err := p.Bind(&s, conf.Key("server"))
+	assert.Nil(t, err)
+	assert.Equal(t, s.BasePath, "/b")
+	assert.Equal(t, s.MaxConns, 10)
+	assert.Equal(t, s.Labels, map[string]string{"zoneName": "cn", "zone_name": "us"})
+	assert.True(t, p.Used("server.basePath"))
+	assert.True(t, p.Used("server.max_conns"))
+
+	// 后设置的写法覆盖先设置的写法，与写法本身无关。
+	p.Set("server.base_path", "/c")
+	err = p.Bind(&s, conf.Key("server"))
+	assert.Nil(t, err)
+	assert.Equal(t, s.BasePath, "/c")
+
+	// 结构体的字段名宽松匹配，嵌套的字段在实际的写法下继续匹配。
+	q := conf.New()
+	q.Set("dataSource.maxConns", 5)
+	q.Set("hosts.node_a.port", 1)
+	q.Set("hosts.node-a.port", 2)
+	var c struct {
+		DataSource struct {
+			MaxConns int `value:"${max-conns}"`
+		} `value:"${data-source}"`
+		Hosts map[string]struct {
+			Port int `value:"${port}"`
+		} `value:"${hosts}"`
+	}
+	err = q.Bind(&c)
+	assert.Nil(t, err)
+	assert.Equal(t, c.DataSource.MaxConns, 5)
+	assert.Equal(t, len(c.Hosts), 2)
+	assert.Equal(t, c.Hosts["node_a"].Port, 1)
+	assert.Equal(t, c.Hosts["node-a"].Port, 2)
+}
+
+func TestProperties_Reload(t *testing.T) {
+
+	p := conf.New()
+	p.Set("a", "1")
+	p.Set("b", "2")
+	p.Set("c.d", "3")
+	assert.Equal(t, p.Get("a"), "1")
+
+	q := conf.New()
+	q.Set("a", "1", conf.Source("a.properties"))
+	q.Set("b", "20")
+	q.Set("e", "5")
+
+	changed := p.Reload(q)
+	assert.Equal(t, changed, []string{"b", "c.d", "e"})
+	assert.Equal(t, p.Get("b"), "20")
+	assert.Nil(t, p.Get("c.d"))
+	assert.Equal(t, p.Origins("a")[0].Source, "a.properties")
+	assert.True(t, p.Used("a"))
+
+	q.Set("e", "6")
+	assert.Equal(t, p.Get("e"), "5")
+}
+
+func TestLoadDocuments(t *testing.T) {
+
+	file := filepath.Join(os.TempDir(), "documents.yaml")
+	err := ioutil.WriteFile(file, []byte("a: 1\n---\na: 2\nb: 3\n--- \n"), 0644)
+	assert.Nil(t, err)
+	defer os.Remove(file)
+
+	docs, err := conf.LoadDocuments(file)
+	assert.Nil(t, err)
+	assert.Equal(t, len(docs), 3)
+	assert.Equal(t, docs[0].Get("a"), "1")
+	assert.Equal(t, docs[1].Get("a"), "2")
+	assert.Equal(t, docs[1].Get("b"), "3")
+	assert.Equal(t, len(docs[2].Keys()), 0)
+	assert.Equal(t, docs[1].Origins("b")[0].Source, file)
+
+	// 块中以 --- 开头的行不是文档分隔符
+	docs, err = conf.ReadDocuments([]byte("a: |\n  x\n  ---\n  y\nb: >\n  ---\n---\nc: 1\n"), ".yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, len(docs), 2)
+	assert.Equal(t, docs[0].Get("a"), "x\n---\ny\n")
+	assert.Equal(t, docs[0].Get("b"), "---\n")
+	assert.Equal(t, docs[1].Get("c"), "1")
+
+	docs, err = conf.ReadDocuments(nil, ".yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, len(docs), 1)
+}
+
+func TestReaders(t *testing.T) {
+
+	testcases := []struct {
+		ext  string
+		data string
+	}{
+		{".json", `{"db":{"url":"a","hosts":["h1","h2"]},"port":8080}`},
+		{".ini", "port = 8080\n; comment\n[db]\nurl = \"a\"\nhosts[0] = h1 ; comment\nhosts[1]: h2\n"},
+		{".env", "# comment\nexport port=8080\ndb.url=\"a\"\ndb.hosts[0]='h1'\ndb.hosts[1]=h2 # comment\n"},
+		{".hcl", "port = 8080\n/* comment */\ndb {\n  url = \"a\" // comment\n  hosts = [\n    \"h1\",\n    \"h2\",\n  ]\n}\n"},
+		{".prop", "port=8080\ndb.url=a\ndb.hosts=h1,h2\n"},
+		{".tml", "port = 8080\n[db]\nurl = \"a\"\nhosts = [\"h1\", \"h2\"]\n"},
+	}
+
+	for _, c := range testcases {
+		p, err := conf.Read([]byte(c.data), c.ext)
+		assert.Nil(t, err)
+		var s struct {
+			Port  int      `value:"${port}"`
+			URL   string   `value:"${db.url}"`
+			Hosts []string `value:"${db.hosts}"`
+		}
+		err = p.Bind(&s)
+		assert.Nil(t, err)
+		assert.Equal(t, s.Port, 8080)
+		assert.Equal(t, s.URL, "a")
+		assert.Equal(t, s.Hosts, []string{"h1", "h2"})
+
+		// 没有扩展名时根据内容推断格式
+		p, err = conf.Read([]byte(c.data), "")
+		assert.Nil(t, err)
+		assert.Equal(t, p.Get("db.url"), "a")
+	}
+}
+
+func TestReaders_Detect(t *testing.T) {
+
+	testcases := []struct {
+		data   string
+		key    string
+		expect string
+	}{
+		// 没有 TOML 特征的内容按照 properties 格式解析，保持值的原样
+		{"version=1.10\nport=8080\n", "version", "1.10"},
+		{"db.url=jdbc:mysql://h/db\nversion = 1.10\n", "version", "1.10"},
+		{"name = \"a\"\nversion = 1.10\n", "version", "1.1"},
+		{"version = 1.10\n[db]\nurl = a\n", "version", "1.10"},
+		{"[db]\nversion = 1.10\n", "db.version", "1.1"},
+		{"[[db]]\nversion = 1.10\n", "db[0].version", "1.1"},
+	}
+
+	for _, c := range testcases {
+		p, err := conf.Read([]byte(c.data), "")
+		assert.Nil(t, err)
+		assert.Equal(t, p.Get(c.key), c.expect)
+	}
+}
+
+func TestReaders_DotenvKeys(t *testing.T) {
+	data := "DB_HOST=localhost\nexport SERVER_BASE__PATH=/api\nLIST_0_NAME=a\nLIST_1_NAME=b\nlog.level=info\n"
+	p, err := conf.Read([]byte(data), ".env")
+	assert.Nil(t, err)
+	assert.Equal(t, p.Get("db.host"), "localhost")
+	assert.Equal(t, p.Get("server.base-path"), "/api")
+	assert.Equal(t, p.Get("list[0].name"), "a")
+	assert.Equal(t, p.Get("list[1].name"), "b")
+	assert.Equal(t, p.Get("log.level"), "info")
+	assert.False(t, p.Has("DB_HOST"))
+	assert.Equal(t, p.Origins("server.base-path")[0].Line, 2)
+}
+
+func TestReaders_JSONNumber(t *testing.T) {
+	p, err := conf.Read([]byte(`{"id":9007199254740993,"big":1000000000000000000000,"ids":[1.50,-2]}`), ".json")
+	assert.Nil(t, err)
+	assert.Equal(t, p.Get("id"), "9007199254740993")
+	assert.Equal(t, p.Get("big"), "1000000000000000000000")
+	assert.Equal(t, p.Get("ids[0]"), "1.50")
+	assert.Equal(t, p.Get("ids[1]"), "-2")
+
+	buf := bytes.NewBuffer(nil)
+	err = p.Write(buf, ".json")
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(buf.String(), `"big": "1000000000000000000000"`))
+
+	_, err = conf.Read([]byte(`{"a":1} {"b":2}`), ".json")
+	assert.Error(t, err, "invalid character after top-level value")
+}
+
+func TestReaders_HCLSyntax(t *testing.T) {
+
+	testcases := []struct {
+		name   string
+		data   string
+		expect map[string]interface{}
+		err    string
+	}{
+		{
+			name:   "heredoc",
+			data:   "s = <<EOF\nline1\n  line2 # not comment\nEOF\nn = 1\n",
+			expect: map[string]interface{}{"s": "line1\n  line2 # not comment\n", "n": "1"},
+		},
+		{
+			name:   "indented heredoc",
+			data:   "s = <<-EOT\n    a\n    b\n    EOT\n",
+			expect: map[string]interface{}{"s": "a\nb\n"},
+		},
+		{
+			name: "nested blocks with labels",
+			data: "a \"x\" y {\n  b \"z\" {\n    c = true\n  }\n}\na \"x\" y {\n  d = null\n}\n",
+			expect: map[string]interface{}{"a": map[string]interface{}{"x": map[string]interface{}{"y": map[string]interface{}{
+				"b": map[string]interface{}{"z": map[string]interface{}{"c": true}},
+				"d": "",
+			}}}},
+		},
+		{
+			name:   "comments inside strings",
+			data:   "url = \"http://h/#x\" // comment\ns = \"/* not */ // not\" # comment\n/* a\n b */\n",
+			expect: map[string]interface{}{"url": "http://h/#x", "s": "/* not */ // not"},
+		},
+		{
+			name:   "numbers",
+			data:   "id = 9007199254740993\nf = -1.5e3\n",
+			expect: map[string]interface{}{"id": "9007199254740993", "f": "-1.5e3"},
+		},
+		{
+			name: "lists and objects",
+			data: "a = [\n  { k: \"v\", n = 1 },\n  [\"x\"]\n]\ns = \"${b:=c}\"\n",
+			expect: map[string]interface{}{
+				"a": []interface{}{map[string]interface{}{"k": "v", "n": "1"}, []interface{}{"x"}},
+				"s": "${b:=c}",
+			},
+		},
+		{name: "missing value", data: "a = \n", err: "line 1: missing value"},
+		{name: "unterminated string", data: "a = \"x\n", err: "line 1: unterminated string"},
+		{name: "invalid string", data: "a = \"\\q\"\n", err: `line 1: invalid string "\\q"`},
+		{name: "missing ]", data: "a = [1, 2\n", err: "line 2: missing ]"},
+		{name: "missing } in object", data: "a = { b = 1\n", err: "line 2: missing }"},
+		{name: "missing = in object", data: "a = { b 1 }\n", err: "line 1: expected = after b"},
+		{name: "missing } in block", data: "db {\n  a = 1\n", err: "line 3: missing }"},
+		{name: "unexpected }", data: "a = 1\n}\n", err: "line 2: unexpected }"},
+		{name: "unexpected character", data: "= 1\n", err: "line 1: unexpected '='"},
+		{name: "missing = or {", data: "a b\n", err: "line 1: expected = or { after a"},
+		{name: "invalid value", data: "a = foo\n", err: `line 1: invalid value "foo"`},
+		{name: "special float", data: "a = nan\n", err: `line 1: invalid value "nan"`},
+		{name: "invalid heredoc", data: "a = <<\n", err: "line 1: invalid heredoc"},
+		{name: "missing heredoc end", data: "a = <<EOF\nx\n", err: "line 3: missing heredoc end EOF"},
+		{name: "unterminated comment", data: "a = 1\n/* x\n", err: "line 2: unterminated comment"},
+		{name: "block conflicts", data: "a = 1\na \"x\" {\n}\n", err: "line 2: block a x conflicts with attribute a"},
+		{name: "attribute conflicts", data: "a {\n}\na = 1\n", err: "line 3: a conflicts with block a"},
+	}
+
+	for _, c := range testcases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := hcl.Read([]byte(c.data))
+			if c.err != "" {
+				assert.Error(t, err, c.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, m, c.expect)
+		})
+	}
+}
+
+func TestReaders_HCL(t *testing.T) {
+	p, err := conf.Read([]byte(`
+service "web" {
+  port = 80
+  tags = { env = "dev", zone: "cn" }
+}
+service "api" {
+  port    = 8080
+  enabled = true
+  ratio   = 0.5
+  script  = <<-EOF
+    echo ${name}
+    EOF
+}
+`), ".hcl")
+	assert.Nil(t, err)
+	assert.Equal(t, p.Get("service.web.port"), "80")
+	assert.Equal(t, p.Get("service.web.tags.zone"), "cn")
+	assert.Equal(t, p.Get("service.api.enabled"), "true")
+	assert.Equal(t, p.Get("service.api.ratio"), "0.5")
+	assert.Equal(t, p.Get("service.api.script"), "echo ${name}\n")
+
+	_, err = conf.Read([]byte("a = {\n"), ".hcl")
+	assert.Error(t, err, "line 2: missing }")
+}
+
+func TestProperties_Write(t *testing.T) {
+
+	p := conf.New()
+	p.Set("port", 8080)
+	p.Set("db.url", "a=b")
+	p.Set("db.hosts[0]", "h1")
+	p.Set("db.hosts[1]", "h2")
+	p.Set("db.password", "ENC(abc)")
+	p.Set("app.home", "${HOME}/app")
+
+	for _, ext := range []string{".yaml", ".yml", ".toml", ".tml", ".properties", ".prop", ".json"} {
+		buf := bytes.NewBuffer(nil)
+		err := p.Write(buf, ext)
+		assert.Nil(t, err)
+
+		// 相同的数据总是得到相同的结果
+		buf2 := bytes.NewBuffer(nil)
+		err = p.Write(buf2, ext)
+		assert.Nil(t, err)
+		assert.Equal(t, buf.String(), buf2.String())
+
+		q, err := conf.Read(buf.Bytes(), ext)
+		assert.Nil(t, err)
+		assert.Equal(t, q.Dump(), p.Dump())
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err := p.Write(buf, ".properties")
+	assert.Nil(t, err)
+	assert.Equal(t, buf.String(), "app.home = ${HOME}/app\n"+
+		"db.hosts[0] = h1\n"+
+		"db.hosts[1] = h2\n"+
+		"db.password = ENC(abc)\n"+
+		"db.url = a=b\n"+
+		"port = 8080\n")
+
+	buf.Reset()
+	err = p.Write(buf, ".json")
+	assert.Nil(t, err)
+	assert.Equal(t, buf.String(), `{
+  "app": {
+    "home": "${HOME}/app"
+  },
+  "db": {
+    "hosts": [
+      "h1",
+      "h2"
+    ],
+    "password": "ENC(abc)",
+    "url": "a=b"
+  },
+  "port": "8080"
+}`)
+
+	err = p.Write(buf, ".xml")
+	assert.Error(t, err, "unsupported file type .xml")
+
+	p.Set("port.value", 80)
+	err = p.Write(buf, ".yaml")
+	assert.Error(t, err, "property port conflicts with other properties")
+
+	// 扁平格式可以同时输出属性值和子属性
+	buf.Reset()
+	err = p.Write(buf, ".properties")
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(buf.String(), "port = 8080\nport.value = 80\n"))
+	q, err := conf.Read(buf.Bytes(), ".properties")
+	assert.Nil(t, err)
+	assert.Equal(t, q.Dump(), p.Dump())
+
+	t.Run("sparse list", func(t *testing.T) {
+		p := conf.New()
+		p.Set("a[2]", "x")
+		p.Set("b[1].c", "y")
+
+		buf := bytes.NewBuffer(nil)
+		err := p.Write(buf, ".toml")
+		assert.Error(t, err, "toml: property (a\\[0\\]|b\\[0\\]) is missing, toml doesn't support null")
+
+		buf.Reset()
+		err = p.Write(buf, ".properties")
+		assert.Nil(t, err)
+		assert.Equal(t, buf.String(), "a[2] = x\nb[1].c = y\n")
+
+		for _, ext := range []string{".json", ".yaml"} {
+			buf.Reset()
+			err = p.Write(buf, ext)
+			assert.Nil(t, err)
+		}
+	})
+}
+
+func TestCatalog_Defaults(t *testing.T) {
+
+	p := conf.New()
+	p.Set("app.name", "test")
+	var c CatalogConfig
+	err := p.Bind(&c)
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	err = p.Catalog().Defaults().Write(buf, ".yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, buf.String(), `web:
+  server:
+    hosts: ""
+    port: "8080"
+    timeout: 1s
+`)
+}
+
+func TestCatalog_ScanSameName(t *testing.T) {
+
+	// 两个函数中定义的同名类型，reflect.Type 的 String 方法返回值相同。
+	first := func() reflect.Type {
+		type config struct {
+			Host string `value:"${host}"`
+		}
+		return reflect.TypeOf(config{})
+	}()
+	second := func() reflect.Type {
+		type config struct {
+			Port int `value:"${port}"`
+		}
+		return reflect.TypeOf(config{})
+	}()
+	assert.Equal(t, first.String(), second.String())
+
+	c := conf.NewCatalog()
+	assert.Nil(t, c.Scan(first, "${db}", ""))
+	assert.Nil(t, c.Scan(second, "${db}", ""))
+	assert.Nil(t, c.Scan(second, "${db}", ""))
+	assert.False(t, c.Unknown("db.host"))
+	assert.False(t, c.Unknown("db.port"))
+	assert.Equal(t, len(c.Metas()), 2)
+}
+
+func TestProperties_Override(t *testing.T) {
+
+	base, err := conf.Read([]byte("hosts:\n  - h1\n  - h2\n  - h3\ndb:\n  url: a\n  user: root\n"), ".yaml")
+	assert.Nil(t, err)
+
+	t.Run("list", func(t *testing.T) {
+		p := conf.New()
+		p.Merge(base)
+		q, err := conf.Read([]byte("hosts[0]=x\nhosts[1]=y\ndb.url=b\n"), ".properties")
+		assert.Nil(t, err)
+		p.Merge(q)
+		assert.Equal(t, p.Keys(), []string{"db.url", "db.user", "hosts[0]", "hosts[1]"})
+		assert.Equal(t, p.Get("db.url"), "b")
+		assert.Equal(t, p.Get("db.user"), "root")
+
+		// 从文件加载时同样整体替换 list
+		err = p.Read([]byte("hosts: [z]"), ".yaml")
+		assert.Nil(t, err)
+		var s struct {
+			Hosts []string `value:"${hosts}"`
+		}
+		err = p.Bind(&s)
+		assert.Nil(t, err)
+		assert.Equal(t, s.Hosts, []string{"z"})
+
+		// 逗号分隔的属性值也会替换 list
+		p.Set("hosts", "a,b")
+		assert.Nil(t, p.Get("hosts[0]"))
+		err = p.Bind(&s)
+		assert.Nil(t, err)
+		assert.Equal(t, s.Hosts, []string{"a", "b"})
+	})
+
+	t.Run("map", func(t *testing.T) {
+		p := conf.New()
+		p.Merge(base)
+		p.Set("db", map[string]string{"url": "b"})
+		assert.Equal(t, p.Get("db.url"), "b")
+		assert.Equal(t, p.Get("db.user"), "root")
+		p.Set("db", map[string]string{"url": "c"}, conf.Replace())
+		assert.Equal(t, p.Get("db.url"), "c")
+		assert.Nil(t, p.Get("db.user"))
+		assert.Equal(t, len(p.Origins("db.url")), 1)
+	})
+
+	t.Run("element", func(t *testing.T) {
+		p := conf.New()
+		p.Merge(base)
+		p.Set("hosts[1]", "x")
+		assert.Equal(t, p.Keys(), []string{"db.url", "db.user", "hosts[0]", "hosts[1]", "hosts[2]"})
+		assert.Equal(t, p.Get("hosts[1]"), "x")
+	})
+}
+
+func TestProperties_Snapshot(t *testing.T) {
+
+	p := conf.New()
+	p.Set("a", "1")
+	p.Set("b", []string{"x", "y"})
+
+	s := p.Snapshot()
+	p.Set("a", "2")
+	p.Set("b", []string{"z"})
+	assert.Equal(t, s.Get("a"), "1")
+	assert.Equal(t, s.Keys(), []string{"a", "b[0]", "b[1]"})
+	assert.Equal(t, p.Get("a"), "2")
+	assert.Equal(t, p.Keys(), []string{"a", "b[0]"})
+
+	// 快照的修改不影响原来的属性列表
+	s.Set("c", "3")
+	assert.Nil(t, p.Get("c"))
+
+	// 快照与原来的属性列表共享读取记录
+	assert.True(t, p.Used("a"))
+}
+
+func TestProperties_Concurrent(t *testing.T) {
+
+	p := conf.New()
+	p.Set("server.port", 8080)
+	p.Set("server.hosts", []string{"a", "b"})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			q := conf.New()
+			q.Set("server.port", 8080+i%2)
+			q.Set("server.hosts", []string{"a", "b"})
+			p.Reload(q)
+			p.Set("server.hosts", []string{"c", "d"})
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		var s struct {
+			Port  int      `value:"${server.port}"`
+			Hosts []string `value:"${server.hosts}"`
+		}
+		err := p.Bind(&s)
+		assert.Nil(t, err)
+		assert.True(t, s.Port == 8080 || s.Port == 8081)
+		assert.Equal(t, len(s.Hosts), 2)
+		assert.NotNil(t, p.Get("server.port"))
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+// flatProperties 改为树形存储之前的实现方式，作为基准测试的对照组：属性保存在读
+// 写锁保护的扁平 map 中，同时记录规范化的 key 和属性值的来源。
+type flatProperties struct {
+	mu sync.RWMutex
+	m  map[string]string
+	r  map[string]string
+	o  map[string][]conf.Origin
+	u  sync.Map
+}
+
+func newFlatProperties() *flatProperties {
+	return &flatProperties{
+		m: make(map[string]string),
+		r: make(map[string]string),
+		o: make(map[string][]conf.Origin),
+	}
+}
+
+func (p *flatProperties) Get(key string) interface{} {
+	p.mu.RLock()
+	if k, ok := p.r[strings.ToLower(key)]; ok {
+		key = k
+	}
+	val, ok := p.m[key]
+	p.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	p.u.Store(key, struct{}{})
+	return val
+}
+
+func (p *flatProperties) Set(key string, val string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.r[strings.ToLower(key)] = key
+	p.m[key] = val
+	p.o[key] = append(p.o[key], conf.Origin{Value: val})
+}
+
+func BenchmarkProperties(b *testing.B) {
+	// 测试结论：读取不需要加锁，查找属性的开销只与 key 的层级有关，get 约 320ns 、
+	// 16B/op ，比 flat-get (约 430ns 、80B/op) 略快并且内存分配更少。修改时需要复
+	// 制路径上的结点，开销与路径上结点的子结点数量成正比：
+	//   set        1000 个子结点  约 190us   57KB/op
+	//   set-narrow 10 个子结点    约 8us     3KB/op
+	//   flat-set                  约 0.7us   0.2KB/op
+	//   batch-set  原地修改       约 4us     1.8KB/op (主要是记录属性值的来源)
+	//   batch-100  一次发布 100 个属性 约 1.2ms 345KB/op ，逐个 set 约 19ms
+	// 因此 Properties 适合读多写少的场景，环境变量、命令行参数等大量零散的属性应该
+	// 通过 Batch 一次性发布。
+
+	p := conf.New()
+	flat := newFlatProperties()
+	for i := 0; i < 1000; i++ {
+		p.Set(fmt.Sprintf("app.module%d.name", i), "module")
+		p.Set(fmt.Sprintf("app.module%d.hosts", i), []string{"a", "b", "c"})
+		flat.Set(fmt.Sprintf("app.module%d.name", i), "module")
+		for j, h := range []string{"a", "b", "c"} {
+			flat.Set(fmt.Sprintf("app.module%d.hosts[%d]", i, j), h)
+		}
+	}
+
+	b.Run("get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Get("app.module500.name")
+		}
+	})
+
+	b.Run("flat-get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			flat.Get("app.module500.name")
+		}
+	})
+
+	b.Run("get-parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				p.Get("app.module500.hosts[1]")
+			}
+		})
+	})
+
+	b.Run("flat-get-parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				flat.Get("app.module500.hosts[1]")
+			}
+		})
+	})
+
+	b.Run("bind", func(b *testing.B) {
+		var s struct {
+			Name  string   `value:"${name}"`
+			Hosts []string `value:"${hosts}"`
+		}
+		for i := 0; i < b.N; i++ {
+			_ = p.Bind(&s, conf.Key("app.module500"))
+		}
+	})
+
+	b.Run("snapshot", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Snapshot()
+		}
+	})
+
+	b.Run("set", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Set("app.module500.name", i)
+		}
+	})
+
+	b.Run("set-narrow", func(b *testing.B) {
+		q := conf.New()
+		for i := 0; i < 10; i++ {
+			q.Set(fmt.Sprintf("app.module%d.name", i), "module")
+		}
+		for i := 0; i < b.N; i++ {
+			q.Set("app.module5.name", i)
+		}
+	})
+
+	b.Run("flat-set", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			flat.Set("app.module500.name", strconv.Itoa(i))
+		}
+	})
+
+	b.Run("batch-set", func(b *testing.B) {
+		batch := conf.NewBatch()
+		for i := 0; i < b.N; i++ {
+			batch.Set("app.module500.name", i)
+		}
+		p.Apply(batch)
+	})
+
+	b.Run("batch-100", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			batch := conf.NewBatch()
+			for j := 0; j < 100; j++ {
+				batch.Set(fmt.Sprintf("app.module%d.name", j*10), j)
+			}
+			p.Apply(batch)
+		}
+	})
+}
+
+func TestProperties_Apply(t *testing.T) {
+
+	p := conf.New()
+	p.Set("db.url", "a", conf.Source("a.yaml"))
+	p.Set("db.user", "root")
+	p.Set("hosts", []string{"h1", "h2", "h3"})
+
+	b := conf.NewBatch()
+	b.Set("db.url", "b", conf.Source("env:GS_DB_URL"))
+	b.Set("hosts", []string{"x"})
+	b.Set("port", 8080)
+	b.Set("port", 9090, conf.Source("cmd:--port"))
+	assert.Nil(t, p.Get("port"))
+
+	s := p.Snapshot()
+	p.Apply(b)
+	assert.Equal(t, p.Keys(), []string{"db.url", "db.user", "hosts[0]", "port"})
+	assert.Equal(t, p.Get("db.url"), "b")
+	assert.Equal(t, p.Get("db.user"), "root")
+	assert.Equal(t, p.Get("port"), "9090")
+	assert.Equal(t, len(p.Origins("db.url")), 2)
+	assert.Equal(t, p.Origins("port")[1].Source, "cmd:--port")
+	assert.Equal(t, s.Get("db.url"), "a")
+
+	// Apply 之后 b 被清空，继续使用不会影响已经发布的属性。
+	b.Set("db.url", "c")
+	assert.Equal(t, p.Get("db.url"), "b")
+	p.Apply(b)
+	assert.Equal(t, p.Get("db.url"), "c")
+	assert.Equal(t, p.Get("port"), "9090")
+}
+
+func TestProperties_TypedGet(t *testing.T) {
+
+	p := conf.New()
+	p.Set("name", "app")
+	p.Set("port", 8080)
+	p.Set("debug", "true")
+	p.Set("timeout", "1m30s")
+	p.Set("hosts", []string{"a", "b"})
+	p.Set("topics", "t1, t2")
+	p.Set("labels", map[string]string{"env": "dev", "zone": "cn"})
+	p.Set("bad", "xyz")
+
+	s, err := p.GetString("name")
+	assert.Nil(t, err)
+	assert.Equal(t, s, "app")
+	i, err := p.GetInt("port")
+	assert.Nil(t, err)
+	assert.Equal(t, i, 8080)
+	b, err := p.GetBool("debug")
+	assert.Nil(t, err)
+	assert.True(t, b)
+	d, err := p.GetDuration("timeout")
+	assert.Nil(t, err)
+	assert.Equal(t, d, 90*time.Second)
+	ss, err := p.GetStringSlice("hosts")
+	assert.Nil(t, err)
+	assert.Equal(t, ss, []string{"a", "b"})
+	ss, err = p.GetStringSlice("topics")
+	assert.Nil(t, err)
+	assert.Equal(t, ss, []string{"t1", "t2"})
+	m, err := p.GetStringMap("labels")
+	assert.Nil(t, err)
+	assert.Equal(t, m, map[string]string{"env": "dev", "zone": "cn"})
+
+	// 默认值
+	i, err = p.GetInt("missing", conf.Def(9090))
+	assert.Nil(t, err)
+	assert.Equal(t, i, 9090)
+	ss, err = p.GetStringSlice("missing", conf.Def("x,y"))
+	assert.Nil(t, err)
+	assert.Equal(t, ss, []string{"x", "y"})
+	ss, err = p.GetStringSlice("missing", conf.Def([]string{"z"}))
+	assert.Nil(t, err)
+	assert.Equal(t, ss, []string{"z"})
+	m, err = p.GetStringMap("missing", conf.Def("k:v"))
+	assert.Nil(t, err)
+	assert.Equal(t, m, map[string]string{"k": "v"})
+
+	// 错误
+	_, err = p.GetInt("missing")
+	assert.True(t, errors.Is(err, conf.ErrNotExist))
+	_, err = p.GetStringMap("missing")
+	assert.True(t, errors.Is(err, conf.ErrNotExist))
+	_, err = p.GetInt("bad")
+	assert.Error(t, err, "property \"bad\": .*")
+	_, err = p.GetDuration("bad")
+	assert.Error(t, err, "property \"bad\": .*")
+
+	assert.True(t, p.Has("name"))
+	assert.False(t, p.Has("labels"))
+	assert.True(t, p.HasPrefix("labels"))
+	assert.True(t, p.HasPrefix("hosts"))
+	assert.False(t, p.HasPrefix("name"))
+}
+
+func TestProperties_Sub(t *testing.T) {
+
+	p := conf.New()
+	p.Set("redis.session.host", "localhost")
+	p.Set("redis.session.port", 6379)
+	p.Set("redis.session.nodes", []string{"n1", "n2"})
+	p.Set("redis.cache.host", "remote")
+
+	sub := p.Sub("redis.session")
+	assert.Equal(t, sub.Keys(), []string{"host", "nodes[0]", "nodes[1]", "port"})
+
+	var c struct {
+		Host  string   `value:"${host}"`
+		Port  int      `value:"${port}"`
+		Nodes []string `value:"${nodes}"`
+	}
+	err := sub.Bind(&c)
+	assert.Nil(t, err)
+	assert.Equal(t, c.Host, "localhost")
+	assert.Equal(t, c.Port, 6379)
+	assert.Equal(t, c.Nodes, []string{"n1", "n2"})
+
+	// 通过子树读取的属性在原属性列表中也被标记为读取过
+	assert.True(t, p.Used("redis.session.host"))
+	assert.False(t, p.Used("redis.cache.host"))
+
+	assert.Equal(t, p.Sub("redis").Sub("cache").Get("host"), "remote")
+	assert.Equal(t, len(p.Sub("missing").Keys()), 0)
+
+	t.Run("reference", func(t *testing.T) {
+		p := conf.New()
+		p.Set("app.host", "example.com")
+		p.Set("redis.host", "${app.host}")
+		p.Set("redis.addr", "${redis.host}:${redis.port:=6379}")
+
+		sub := p.Sub("redis")
+		s, err := sub.Resolve("${addr}")
+		assert.Nil(t, err)
+		assert.Equal(t, s, "example.com:6379")
+		s, err = sub.Sub("missing").Resolve("${missing:=${host}}")
+		assert.Error(t, err, "property \"host\" not exist")
+
+		var c struct {
+			Host string `value:"${host}"`
+			Addr string `value:"${addr}"`
+			Port int    `value:"${port:=${timeout:=80}}"`
+		}
+		err = sub.Bind(&c)
+		assert.Nil(t, err)
+		assert.Equal(t, c.Host, "example.com")
+		assert.Equal(t, c.Addr, "example.com:6379")
+		assert.Equal(t, c.Port, 80)
+		assert.True(t, p.Used("app.host"))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		p := conf.New()
+		p.Set("servers", "default")
+		p.Set("servers[0].host", "a")
+		p.Set("servers[1].host", "b")
+		assert.Equal(t, len(p.Sub("servers").Keys()), 0)
+		assert.Equal(t, p.Sub("servers[1]").Keys(), []string{"host"})
+		assert.Equal(t, p.Sub("servers[1]").Get("host"), "b")
+	})
+}
@@ -17,8 +17,16 @@
 package conf
 
 import (
+	"encoding"
 	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-spring/spring-stl/cast"
@@ -34,6 +42,47 @@ func init() {
 
 	// time.Duration 转换函数，支持 "ns", "us" (or "µs"), "ms", "s", "m", "h" 等。
 	Convert(func(s string) (time.Duration, error) { return cast.ToDurationE(s) })
+
+	// net.IP 转换函数，支持 IPv4 和 IPv6 格式。
+	Convert(func(s string) (net.IP, error) {
+		if ip := net.ParseIP(s); ip != nil {
+			return ip, nil
+		}
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	})
+
+	// *net.IPNet 转换函数，支持 CIDR 格式，如 192.168.0.0/16 。
+	Convert(func(s string) (*net.IPNet, error) {
+		_, n, err := net.ParseCIDR(s)
+		return n, err
+	})
+
+	// url.URL 转换函数。
+	Convert(func(s string) (url.URL, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return url.URL{}, err
+		}
+		return *u, nil
+	})
+
+	// *url.URL 转换函数。
+	Convert(url.Parse)
+
+	// *regexp.Regexp 转换函数。
+	Convert(regexp.Compile)
+
+	// ByteSize 转换函数，支持 10MB 这样的格式。
+	Convert(ParseByteSize)
+
+	// os.FileMode 转换函数，使用八进制格式，如 0644 。
+	Convert(func(s string) (os.FileMode, error) {
+		m, err := strconv.ParseUint(s, 8, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid file mode %q", s)
+		}
+		return os.FileMode(m), nil
+	})
 }
 
 func validConverter(t reflect.Type) bool {
@@ -41,11 +90,11 @@ func validConverter(t reflect.Type) bool {
 		t.NumIn() == 1 &&
 		t.In(0).Kind() == reflect.String &&
 		t.NumOut() == 2 &&
-		util.IsValueType(t.Out(0)) &&
 		util.IsErrorType(t.Out(1))
 }
 
-// Convert 注册类型转换器，转换器的函数原型为 func(string)(type,error) 。
+// Convert 注册全局的类型转换器，转换器的函数原型为 func(string)(type,error) ，
+// type 可以是指针类型。
 func Convert(fn interface{}) {
 	t := reflect.TypeOf(fn)
 	if !validConverter(t) {
@@ -53,3 +102,133 @@ func Convert(fn interface{}) {
 	}
 	converters[t.Out(0)] = fn
 }
+
+// Convert 注册只对当前属性列表有效的类型转换器，它的优先级高于全局的类型转换器，
+// 转换器的函数原型为 func(string)(type,error) 。
+func (p *Properties) Convert(fn interface{}) {
+	t := reflect.TypeOf(fn)
+	if !validConverter(t) {
+		panic(errors.New("fn must be func(string)(type,error)"))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := make(map[reflect.Type]interface{})
+	if cv, ok := p.cv.Load().(map[reflect.Type]interface{}); ok {
+		for k, v := range cv {
+			m[k] = v
+		}
+	}
+	m[t.Out(0)] = fn
+	p.cv.Store(m)
+}
+
+type convertFunc func(s string) (reflect.Value, error)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// converter 返回 t 类型的转换函数，依次查找当前属性列表的转换器、全局的转换器以
+// 及 t 是否实现了 encoding.TextUnmarshaler 接口，都没有时返回 nil 。
+func (p *Properties) converter(t reflect.Type) convertFunc {
+	if p != nil {
+		cv, _ := p.cv.Load().(map[reflect.Type]interface{})
+		if fn, ok := cv[t]; ok {
+			return funcConverter(fn)
+		}
+	}
+	return globalConverter(t)
+}
+
+func globalConverter(t reflect.Type) convertFunc {
+
+	if fn, ok := converters[t]; ok {
+		return funcConverter(fn)
+	}
+
+	switch {
+	case t.Kind() == reflect.Ptr && t.Implements(textUnmarshalerType):
+		return func(s string) (reflect.Value, error) {
+			v := reflect.New(t.Elem())
+			err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+			return v, err
+		}
+	case t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return func(s string) (reflect.Value, error) {
+			v := reflect.New(t)
+			err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+			return v.Elem(), err
+		}
+	}
+	return nil
+}
+
+func funcConverter(fn interface{}) convertFunc {
+	fnValue := reflect.ValueOf(fn)
+	return func(s string) (reflect.Value, error) {
+		out := fnValue.Call([]reflect.Value{reflect.ValueOf(s)})
+		if !out[1].IsNil() {
+			return reflect.Value{}, out[1].Interface().(error)
+		}
+		return out[0], nil
+	}
+}
+
+// isValueType 返回 t 是否可以作为属性绑定的目标，除了 util.IsValueType 认可的
+// 类型，存在转换器的类型以及它们的一层集合类型也可以。
+func isValueType(t reflect.Type, fn func(reflect.Type) convertFunc) bool {
+	if fn(t) != nil {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		if fn(t.Elem()) != nil {
+			return true
+		}
+	}
+	return util.IsValueType(t)
+}
+
+// ByteSize 字节数，可以从 10MB 这样的字符串转换而来。
+type ByteSize int64
+
+const (
+	B  ByteSize = 1
+	KB          = B << 10
+	MB          = KB << 10
+	GB          = MB << 10
+	TB          = GB << 10
+)
+
+// ParseByteSize 解析 10MB 这样的字符串，支持 B 、KB 、MB 、GB 、TB 单位，单位
+// 大小写不敏感并且按照 1024 进制计算，没有单位时表示字节数。
+func ParseByteSize(s string) (ByteSize, error) {
+
+	str := strings.ToUpper(strings.TrimSpace(s))
+	i := strings.IndexFunc(str, func(r rune) bool { return r < '0' || r > '9' })
+	if i == 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	num, unit := str, ""
+	if i > 0 {
+		num, unit = str[:i], strings.TrimSpace(str[i:])
+	}
+
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	switch unit {
+	case "", "B":
+		return ByteSize(n), nil
+	case "K", "KB":
+		return ByteSize(n) * KB, nil
+	case "M", "MB":
+		return ByteSize(n) * MB, nil
+	case "G", "GB":
+		return ByteSize(n) * GB, nil
+	case "T", "TB":
+		return ByteSize(n) * TB, nil
+	}
+	return 0, fmt.Errorf("invalid byte size %q", s)
+}
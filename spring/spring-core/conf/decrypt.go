@@ -0,0 +1,60 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Decryptor 属性值的解密器，s 是 ENC(...) 括号中的密文。
+type Decryptor interface {
+	Decrypt(s string) (string, error)
+}
+
+var decryptor = struct {
+	sync.RWMutex
+	d Decryptor
+}{}
+
+// SetDecryptor 设置属性值的解密器，Get 、Bind 以及 Resolve 方法在读取 ENC(...)
+// 形式的属性值时使用它进行解密，属性列表中保存的始终是密文。
+func SetDecryptor(d Decryptor) {
+	decryptor.Lock()
+	defer decryptor.Unlock()
+	decryptor.d = d
+}
+
+// IsEncrypted 返回 s 是否为 ENC(...) 形式的加密属性值。
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, "ENC(") && strings.HasSuffix(s, ")")
+}
+
+// decrypt 解密 ENC(...) 形式的属性值，其他形式的属性值原样返回。
+func decrypt(s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+	decryptor.RLock()
+	d := decryptor.d
+	decryptor.RUnlock()
+	if d == nil {
+		return "", errors.New("no decryptor for encrypted value")
+	}
+	return d.Decrypt(s[4 : len(s)-1])
+}
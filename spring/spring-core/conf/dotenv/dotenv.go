@@ -0,0 +1,127 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dotenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Read 将 .env 格式的字节数组解析成 map 数据。属性使用 KEY=VALUE 的形式，可以
+// 带有 export 前缀，以 # 开头的行是注释。双引号中的属性值支持转义字符，单引号中的
+// 属性值保持原样，没有引号的属性值中以空白字符加 # 开始的部分是注释。属性名按照
+// 环境变量的规则使用 PropKey 转换，比如 DB_HOST 转换成 db.host 。
+func Read(b []byte) (map[string]interface{}, error) {
+	ret := make(map[string]interface{})
+	err := parse(b, func(key string, value string, line int) {
+		ret[key] = value
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Lines 返回 .env 格式的字节数组中每个属性所在的行号。
+func Lines(b []byte) (map[string]int, error) {
+	ret := make(map[string]int)
+	err := parse(b, func(key string, value string, line int) {
+		if _, ok := ret[key]; !ok {
+			ret[key] = line
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// PropKey 将环境变量名转换成属性名，单个下划线转换成点号，两个下划线转换成短横
+// 线，纯数字的部分转换成下标，并且统一转换成小写，比如 LIST_0_NAME 转换成
+// list[0].name ，SERVER_BASE__PATH 转换成 server.base-path 。
+func PropKey(name string) string {
+	var sb strings.Builder
+	name = strings.ReplaceAll(strings.ToLower(name), "__", "-")
+	for i, s := range strings.Split(name, "_") {
+		if i > 0 && s != "" && strings.Trim(s, "0123456789") == "" {
+			sb.WriteString("[" + s + "]")
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+func parse(b []byte, fn func(key string, value string, line int)) error {
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		j := strings.Index(line, "=")
+		if j <= 0 {
+			return fmt.Errorf("line %d: invalid property %q", i+1, line)
+		}
+		key := PropKey(strings.TrimSpace(line[:j]))
+		value, err := parseValue(strings.TrimSpace(line[j+1:]))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+		fn(key, value, i+1)
+	}
+	return nil
+}
+
+func parseValue(s string) (string, error) {
+	if s == "" {
+		return s, nil
+	}
+	switch s[0] {
+	case '"':
+		if i := closingQuote(s); i > 0 {
+			return strconv.Unquote(s[:i+1])
+		}
+		return "", fmt.Errorf("unterminated value %s", s)
+	case '\'':
+		if i := strings.IndexByte(s[1:], '\''); i >= 0 {
+			return s[1 : i+1], nil
+		}
+		return "", fmt.Errorf("unterminated value %s", s)
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s, nil
+}
+
+// closingQuote 返回与开头的双引号对应的结束双引号的位置，没有找到时返回 -1 。
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-spring/spring-stl/cast"
+)
+
+// value 返回 key 对应的属性值，属性值不存在时返回默认值，都不存在时返回错误。
+func (p *Properties) value(key string, opts []GetOption) (interface{}, error) {
+	key = strings.TrimPrefix(key, RootKey+".")
+	val, ok, err := p.get(key)
+	if err != nil {
+		return nil, fmt.Errorf("property %q decrypt error: %w", key, err)
+	}
+	if ok {
+		return val, nil
+	}
+	arg := getArg{}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+	if arg.def != nil {
+		return arg.def, nil
+	}
+	return nil, fmt.Errorf("property %q %w", key, ErrNotExist)
+}
+
+// GetString 返回 key 对应的 string 类型的属性值，属性值不存在时返回 Def 设置的
+// 默认值，没有默认值时返回 ErrNotExist 错误。
+func (p *Properties) GetString(key string, opts ...GetOption) (string, error) {
+	v, err := p.value(key, opts)
+	if err != nil {
+		return "", err
+	}
+	return cast.ToStringE(v)
+}
+
+// GetInt 返回 key 对应的 int 类型的属性值，属性值不存在时返回 Def 设置的默认值，
+// 没有默认值时返回 ErrNotExist 错误。
+func (p *Properties) GetInt(key string, opts ...GetOption) (int, error) {
+	v, err := p.value(key, opts)
+	if err != nil {
+		return 0, err
+	}
+	i, err := cast.ToInt64E(v)
+	if err != nil {
+		return 0, fmt.Errorf("property %q: %w", key, err)
+	}
+	return int(i), nil
+}
+
+// GetBool 返回 key 对应的 bool 类型的属性值，属性值不存在时返回 Def 设置的默认
+// 值，没有默认值时返回 ErrNotExist 错误。
+func (p *Properties) GetBool(key string, opts ...GetOption) (bool, error) {
+	v, err := p.value(key, opts)
+	if err != nil {
+		return false, err
+	}
+	b, err := cast.ToBoolE(v)
+	if err != nil {
+		return false, fmt.Errorf("property %q: %w", key, err)
+	}
+	return b, nil
+}
+
+// GetDuration 返回 key 对应的 time.Duration 类型的属性值，属性值的格式与
+// time.ParseDuration 相同，属性值不存在时返回 Def 设置的默认值，没有默认值时返
+// 回 ErrNotExist 错误。
+func (p *Properties) GetDuration(key string, opts ...GetOption) (time.Duration, error) {
+	v, err := p.value(key, opts)
+	if err != nil {
+		return 0, err
+	}
+	d, err := cast.ToDurationE(v)
+	if err != nil {
+		return 0, fmt.Errorf("property %q: %w", key, err)
+	}
+	return d, nil
+}
+
+// GetStringSlice 返回 key 对应的 []string 类型的属性值，属性值可以是 a[0] 形式
+// 的列表，也可以是逗号分隔的字符串。属性值不存在时返回 Def 设置的默认值，默认值可
+// 以是 []string 或者逗号分隔的字符串，没有默认值时返回 ErrNotExist 错误。
+func (p *Properties) GetStringSlice(key string, opts ...GetOption) ([]string, error) {
+	var s []string
+	if err := p.getValue(key, &s, opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetStringMap 返回 key 对应的 map[string]string 类型的属性值，map 的 key 是子
+// 属性的相对路径。属性值不存在时返回 Def 设置的默认值，默认值可以是
+// map[string]string 或者 k:v 对形式的字符串，没有默认值时返回 ErrNotExist 错误。
+func (p *Properties) GetStringMap(key string, opts ...GetOption) (map[string]string, error) {
+	var m map[string]string
+	if err := p.getValue(key, &m, opts); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// getValue 将 key 对应的属性及其子属性绑定到 i 上，i 必须是一个指针。与 Bind 方
+// 法不同的是该方法不记录属性元数据。
+func (p *Properties) getValue(key string, i interface{}, opts []GetOption) error {
+
+	key = strings.TrimPrefix(key, RootKey+".")
+	v := reflect.ValueOf(i).Elem()
+	q := p.Snapshot()
+
+	tag := "${" + key + "}"
+	if !q.Has(key) && !q.HasPrefix(key) {
+		arg := getArg{}
+		for _, opt := range opts {
+			opt(&arg)
+		}
+		if arg.def == nil {
+			return fmt.Errorf("property %q %w", key, ErrNotExist)
+		}
+		if d := reflect.ValueOf(arg.def); d.Type().AssignableTo(v.Type()) {
+			v.Set(d)
+			return nil
+		}
+		tag = "${" + key + ":=" + cast.ToString(arg.def) + "}"
+	}
+
+	opt := bindOption{typ: v.Type(), path: key, errs: &ValidateError{}}
+	return bind(q, v, tag, opt)
+}
+
+// Has 返回 key 对应的属性值是否存在，不包括只有子属性的情况。
+func (p *Properties) Has(key string) bool {
+	n := p.lookup(strings.TrimPrefix(key, RootKey+"."))
+	return n != nil && n.hasValue
+}
+
+// HasPrefix 返回是否存在 prefix.sub 或者 prefix[i] 形式的子属性。
+func (p *Properties) HasPrefix(prefix string) bool {
+	n := p.lookup(strings.TrimPrefix(prefix, RootKey+"."))
+	return n != nil && n.hasChildren()
+}
+
+// Sub 返回以 prefix 为根的属性列表，比如 prefix 为 redis 时 redis.host 在返回
+// 的属性列表中是 host ，这样可以将一部分属性交给只关心这部分属性的代码使用。返回
+// 的属性列表是 prefix 对应子树的快照，它与当前属性列表共享读取记录以及类型转换
+// 器，但是使用独立的属性元数据。属性值是按照完整的属性名书写的，因此属性值中的引
+// 用在创建子树时的完整属性列表中解析，可以引用 prefix 之外的属性，而 Bind 等方
+// 法的 ${key} 以及默认值中的引用仍然在子树中解析。prefix 自身的属性值以及 list
+// 形式的子属性没有对应的属性名，不包含在返回的属性列表中，需要使用 prefix[i]
+// 获取 list 的元素。
+func (p *Properties) Sub(prefix string) *Properties {
+	prefix = strings.TrimPrefix(prefix, RootKey+".")
+	base := p.base
+	if base == nil {
+		base = p.Snapshot()
+	}
+	q := &Properties{c: NewCatalog(), u: p.u, pre: p.pre + prefix + ".", base: base}
+	q.c.converter = q.converter
+	n := p.lookup(prefix)
+	if n == nil {
+		n = &node{}
+	}
+	root := *n
+	root.name, root.value, root.hasValue, root.origins, root.elems = "", "", false, nil, nil
+	q.root.Store(&root)
+	if cv := p.cv.Load(); cv != nil {
+		q.cv.Store(cv)
+	}
+	return q
+}
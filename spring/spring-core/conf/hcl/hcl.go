@@ -0,0 +1,357 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hcl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Read 将 hcl 格式的字节数组解析成 map 数据。只支持 HCL 中适合描述配置的子集：
+//
+//   - key = value 或者 key: value 形式的属性，key 可以是标识符或者字符串，标识符
+//     可以包含字母、数字、_ 、- 和 . ；
+//   - name "label" ... { ... } 形式的块，块的名称和标签依次作为属性名的前缀，同名
+//     的块会合并在一起，块和同名的属性冲突时返回错误；
+//   - 字符串、数字、true 、false 、null 、列表以及 { key = value } 形式的对象，
+//     数字按照原样保存为字符串，null 保存为空字符串；
+//   - <<EOF 形式的多行字符串，<<-EOF 形式会去掉每行开头的空白字符；
+//   - # 、// 以及 /* */ 形式的注释，字符串中的注释符号不是注释。
+//
+// 不支持表达式、函数调用、for 表达式以及变量引用，字符串中的 ${...} 保持原样，由
+// 属性列表负责解析其中的引用。同名的属性后出现的覆盖先出现的。
+func Read(b []byte) (map[string]interface{}, error) {
+	p := &parser{s: string(b)}
+	m := make(map[string]interface{})
+	err := p.parseBody(m, false)
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type parser struct {
+	s   string
+	i   int
+	err error // 跳过注释时发生的错误，优先于其他错误返回
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.s[:p.i], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+// skip 跳过空白字符和注释，newline 为 false 时遇到换行符停止。
+func (p *parser) skip(newline bool) {
+	for !p.eof() {
+		c := p.s[p.i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.i++
+		case c == '\n':
+			if !newline {
+				return
+			}
+			p.i++
+		case c == '#' || strings.HasPrefix(p.s[p.i:], "//"):
+			for !p.eof() && p.s[p.i] != '\n' {
+				p.i++
+			}
+		case strings.HasPrefix(p.s[p.i:], "/*"):
+			j := strings.Index(p.s[p.i+2:], "*/")
+			if j < 0 {
+				if p.err == nil {
+					p.err = p.errorf("unterminated comment")
+				}
+				p.i = len(p.s)
+				return
+			}
+			p.i += j + 4
+		default:
+			return
+		}
+	}
+}
+
+// parseBody 解析属性和块，直到文件结束，nested 为 true 时直到遇到 } 为止。
+func (p *parser) parseBody(m map[string]interface{}, nested bool) error {
+	for {
+		p.skip(true)
+		if p.eof() {
+			if nested {
+				return p.errorf("missing }")
+			}
+			return nil
+		}
+		if p.s[p.i] == '}' {
+			if !nested {
+				return p.errorf("unexpected }")
+			}
+			p.i++
+			return nil
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+
+		p.skip(false)
+		if !p.eof() && (p.s[p.i] == '=' || p.s[p.i] == ':') {
+			p.i++
+			v, err := p.parseValue()
+			if err != nil {
+				return err
+			}
+			if _, ok := m[key].(map[string]interface{}); ok {
+				return p.errorf("%s conflicts with block %s", key, key)
+			}
+			m[key] = v
+			continue
+		}
+
+		keys := []string{key}
+		for {
+			p.skip(false)
+			if p.eof() || p.s[p.i] == '\n' {
+				return p.errorf("expected = or { after %s", key)
+			}
+			if p.s[p.i] == '{' {
+				p.i++
+				break
+			}
+			label, err := p.parseKey()
+			if err != nil {
+				return err
+			}
+			keys = append(keys, label)
+		}
+
+		sub, err := p.blockMap(m, keys)
+		if err != nil {
+			return err
+		}
+		if err = p.parseBody(sub, true); err != nil {
+			return err
+		}
+	}
+}
+
+// blockMap 返回块对应的 map 数据，同名的块共用一个 map 数据。
+func (p *parser) blockMap(m map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok {
+			v = make(map[string]interface{})
+			m[k] = v
+		}
+		sub, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, p.errorf("block %s conflicts with attribute %s", strings.Join(keys, " "), k)
+		}
+		m = sub
+	}
+	return m, nil
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '-' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// parseKey 解析标识符或者字符串形式的属性名以及块的标签。
+func (p *parser) parseKey() (string, error) {
+	if p.s[p.i] == '"' {
+		return p.parseString()
+	}
+	start := p.i
+	for !p.eof() && isIdent(p.s[p.i]) {
+		p.i++
+	}
+	if start == p.i {
+		return "", p.errorf("unexpected %q", p.s[p.i])
+	}
+	return p.s[start:p.i], nil
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	p.skip(false)
+	if p.eof() || p.s[p.i] == '\n' {
+		return nil, p.errorf("missing value")
+	}
+	switch {
+	case p.s[p.i] == '"':
+		return p.parseString()
+	case p.s[p.i] == '[':
+		return p.parseList()
+	case p.s[p.i] == '{':
+		return p.parseObject()
+	case strings.HasPrefix(p.s[p.i:], "<<"):
+		return p.parseHeredoc()
+	}
+
+	start := p.i
+	for !p.eof() && (isIdent(p.s[p.i]) || p.s[p.i] == '+') {
+		p.i++
+	}
+	s := p.s[start:p.i]
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return "", nil
+	}
+	if isNumber(s) {
+		return s, nil
+	}
+	p.i = start
+	return nil, p.errorf("invalid value %q", s)
+}
+
+// isNumber 返回 s 是否是十进制形式的数字，不接受 inf 、nan 这样的特殊值。
+func isNumber(s string) bool {
+	t := strings.TrimLeft(s, "+-")
+	if t == "" || t[0] < '0' || t[0] > '9' || len(s)-len(t) > 1 {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func (p *parser) parseString() (string, error) {
+	start := p.i
+	for p.i++; !p.eof(); p.i++ {
+		switch p.s[p.i] {
+		case '\\':
+			p.i++
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '"':
+			p.i++
+			s, err := strconv.Unquote(p.s[start:p.i])
+			if err != nil {
+				return "", p.errorf("invalid string %s", p.s[start:p.i])
+			}
+			return s, nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseList() ([]interface{}, error) {
+	ret := make([]interface{}, 0)
+	for p.i++; ; {
+		p.skip(true)
+		if p.eof() {
+			return nil, p.errorf("missing ]")
+		}
+		if p.s[p.i] == ']' {
+			p.i++
+			return ret, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, v)
+		p.skip(true)
+		if !p.eof() && p.s[p.i] == ',' {
+			p.i++
+		}
+	}
+}
+
+func (p *parser) parseObject() (map[string]interface{}, error) {
+	ret := make(map[string]interface{})
+	for p.i++; ; {
+		p.skip(true)
+		if p.eof() {
+			return nil, p.errorf("missing }")
+		}
+		if p.s[p.i] == '}' {
+			p.i++
+			return ret, nil
+		}
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+		if p.eof() || (p.s[p.i] != '=' && p.s[p.i] != ':') {
+			return nil, p.errorf("expected = after %s", key)
+		}
+		p.i++
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		ret[key] = v
+		p.skip(true)
+		if !p.eof() && p.s[p.i] == ',' {
+			p.i++
+		}
+	}
+}
+
+// parseHeredoc 解析 <<EOF 形式的多行字符串，<<-EOF 形式会去掉每行开头的空白字符。
+func (p *parser) parseHeredoc() (string, error) {
+	p.i += 2
+	indent := false
+	if !p.eof() && p.s[p.i] == '-' {
+		indent = true
+		p.i++
+	}
+	end := strings.IndexByte(p.s[p.i:], '\n')
+	if end < 0 {
+		return "", p.errorf("invalid heredoc")
+	}
+	marker := strings.TrimSpace(p.s[p.i : p.i+end])
+	if marker == "" {
+		return "", p.errorf("invalid heredoc")
+	}
+	p.i += end + 1
+	var lines []string
+	for !p.eof() {
+		end = strings.IndexByte(p.s[p.i:], '\n')
+		if end < 0 {
+			end = len(p.s) - p.i
+		}
+		line := strings.TrimSuffix(p.s[p.i:p.i+end], "\r")
+		p.i += end
+		if strings.TrimSpace(line) == marker {
+			return strings.Join(lines, "\n") + "\n", nil
+		}
+		if p.i < len(p.s) {
+			p.i++
+		}
+		if indent {
+			line = strings.TrimLeft(line, " \t")
+		}
+		lines = append(lines, line)
+	}
+	return "", p.errorf("missing heredoc end %s", marker)
+}
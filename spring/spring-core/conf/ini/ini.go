@@ -0,0 +1,89 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ini
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Read 将 ini 格式的字节数组解析成 map 数据。[section] 作为属性名的前缀，属性使
+// 用 key=value 或者 key: value 的形式，以 ; 或者 # 开头的行是注释，没有引号的
+// 属性值中以空白字符加 ; 或者 # 开始的部分也是注释。
+func Read(b []byte) (map[string]interface{}, error) {
+	ret := make(map[string]interface{})
+	err := parse(b, func(key string, value string, line int) {
+		ret[key] = value
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Lines 返回 ini 格式的字节数组中每个属性所在的行号。
+func Lines(b []byte) (map[string]int, error) {
+	ret := make(map[string]int)
+	err := parse(b, func(key string, value string, line int) {
+		if _, ok := ret[key]; !ok {
+			ret[key] = line
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func parse(b []byte, fn func(key string, value string, line int)) error {
+	section := ""
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("line %d: invalid section %q", i+1, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		j := strings.IndexAny(line, "=:")
+		if j <= 0 {
+			return fmt.Errorf("line %d: invalid property %q", i+1, line)
+		}
+		key := strings.TrimSpace(line[:j])
+		if section != "" {
+			key = section + "." + key
+		}
+		fn(key, parseValue(strings.TrimSpace(line[j+1:])), i+1)
+	}
+	return nil
+}
+
+func parseValue(s string) string {
+	if n := len(s); n >= 2 && (s[0] == '"' || s[0] == '\'') && s[n-1] == s[0] {
+		return s[1 : n-1]
+	}
+	for _, sep := range []string{" ;", " #", "\t;", "\t#"} {
+		if i := strings.Index(s, sep); i >= 0 {
+			s = strings.TrimSpace(s[:i])
+		}
+	}
+	return s
+}
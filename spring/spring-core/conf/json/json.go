@@ -0,0 +1,61 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Read 将 json 格式的字节数组解析成 map 数据，根节点必须是对象。数字按照原样保存
+// 为字符串，避免超过 2^53 的整数在转换为 float64 时丢失精度。
+func Read(b []byte) (map[string]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	m := make(map[string]interface{})
+	if err := d.Decode(&m); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+	return Normalize(m).(map[string]interface{}), nil
+}
+
+// Normalize 将 json.Number 类型的值转换为字符串，其他值保持不变。
+func Normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		return x.String()
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = Normalize(e)
+		}
+	case []interface{}:
+		for i, e := range x {
+			x[i] = Normalize(e)
+		}
+	}
+	return v
+}
+
+// Write 将 map 数据序列化成 json 格式的字节数组，map 的 key 按照字典序排列。
+func Write(m map[string]interface{}) ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
@@ -0,0 +1,299 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Meta 属性的元数据，从 value 标签中提取。
+type Meta struct {
+	Key        string `json:"key"`                 // 属性名
+	Type       string `json:"type"`                // 绑定目标的 Go 类型
+	Default    string `json:"default,omitempty"`   // ${key:=def} 中的默认值
+	HasDefault bool   `json:"has_default"`         // 是否具有默认值
+	Struct     string `json:"struct,omitempty"`    // 字段所属的结构体
+	Field      string `json:"field,omitempty"`     // 绑定目标的路径
+	FileLine   string `json:"file_line,omitempty"` // 发起绑定的代码位置
+}
+
+// Catalog 属性元数据的目录，同一个属性只记录第一次扫描到的元数据。
+type Catalog struct {
+	mutex     sync.Mutex
+	converter func(t reflect.Type) convertFunc
+	metas     map[string]*Meta
+	patterns  map[string]*regexp.Regexp // 属性名对应的正则表达式，扫描时编译
+	prefixes  map[string]*regexp.Regexp // 结构体绑定的属性前缀对应的正则表达式
+	scanned   map[scanKey]struct{}
+}
+
+// scanKey 扫描过的类型和 tag ，类型的名称可能重复，比如不同包或者不同函数中定义
+// 的同名类型，因此使用 reflect.Type 本身区分。
+type scanKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// NewCatalog 返回一个空的属性元数据目录。
+func NewCatalog() *Catalog {
+	return &Catalog{
+		converter: globalConverter,
+		metas:     make(map[string]*Meta),
+		patterns:  make(map[string]*regexp.Regexp),
+		prefixes:  make(map[string]*regexp.Regexp),
+		scanned:   make(map[scanKey]struct{}),
+	}
+}
+
+type scanOption struct {
+	key      string // 完整的属性名
+	path     string // 绑定对象的路径
+	owner    string // 字段所属的结构体
+	fileLine string // 发起绑定的代码位置
+}
+
+// Scan 扫描使用 tag 对 t 类型进行属性绑定时涉及的所有属性，fileLine 是发起绑定
+// 的代码位置。和 Bind 的区别是 Scan 不需要属性值，只是根据类型和 tag 进行推导，
+// map 的 key 使用 * 表示，slice 和 array 的下标使用 [*] 表示。
+func (c *Catalog) Scan(t reflect.Type, tag string, fileLine string) error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	id := scanKey{t: t, tag: tag}
+	if _, ok := c.scanned[id]; ok {
+		return nil
+	}
+	c.scanned[id] = struct{}{}
+
+	s := t.Name()
+	if s == "" {
+		s = t.String()
+	}
+	return c.scanTag(t, tag, scanOption{path: s, fileLine: fileLine})
+}
+
+func (c *Catalog) scanTag(t reflect.Type, tag string, opt scanOption) error {
+
+	if !validTag(tag) {
+		return fmt.Errorf("%s 属性绑定字符串 %s 语法错误", opt.path, tag)
+	}
+
+	key, def, hasDef := parseTag(tag)
+	if key == "" {
+		return nil // 匿名属性不需要记录
+	}
+
+	if opt.key == "" {
+		opt.key = key
+	} else {
+		opt.key = opt.key + "." + key
+	}
+	if opt.key == RootKey {
+		opt.key = ""
+	} else {
+		opt.key = strings.TrimPrefix(opt.key, RootKey+".")
+	}
+
+	return c.scanType(t, def, hasDef, opt)
+}
+
+func (c *Catalog) scanType(t reflect.Type, def string, hasDef bool, opt scanOption) error {
+
+	if c.converter(t) == nil {
+		switch t.Kind() {
+		case reflect.Struct:
+			return c.scanStruct(t, opt)
+		case reflect.Map, reflect.Slice, reflect.Array:
+			if opt.key == "" {
+				return nil // 绑定全部属性时无法推导
+			}
+			if et := t.Elem(); et.Kind() == reflect.Struct {
+				if c.converter(et) == nil {
+					subOpt := opt
+					if t.Kind() == reflect.Map {
+						subOpt.key = joinKey(opt.key, "*")
+					} else {
+						subOpt.key = opt.key + "[*]"
+					}
+					return c.scanStruct(et, subOpt)
+				}
+			}
+			if t.Kind() == reflect.Map {
+				opt.key = joinKey(opt.key, "*")
+				t = t.Elem()
+			}
+		}
+	}
+
+	if opt.key == "" {
+		return nil
+	}
+
+	if _, ok := c.metas[opt.key]; !ok {
+		c.metas[opt.key] = &Meta{
+			Key:        opt.key,
+			Type:       t.String(),
+			Default:    def,
+			HasDefault: hasDef,
+			Struct:     opt.owner,
+			Field:      opt.path,
+			FileLine:   opt.fileLine,
+		}
+		c.patterns[opt.key] = regexp.MustCompile(keyRegexp(canonicalKey(opt.key)) + `(\[\d+\])*$`)
+	}
+	return nil
+}
+
+func (c *Catalog) scanStruct(t reflect.Type, opt scanOption) error {
+	if _, ok := c.prefixes[opt.key]; !ok && opt.key != "" {
+		c.prefixes[opt.key] = regexp.MustCompile(keyRegexp(canonicalKey(opt.key)) + `[.\[]`)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+
+		subOpt := opt
+		subOpt.owner = t.String()
+		subOpt.path = opt.path + "." + ft.Name
+
+		if tag, ok := ft.Tag.Lookup("value"); ok {
+			if !isValueType(ft.Type, c.converter) {
+				return fmt.Errorf("%s 属性绑定的目标必须是值类型", subOpt.path)
+			}
+			if err := c.scanTag(ft.Type, tag, subOpt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if ft.Type.Kind() == reflect.Struct {
+			if err := c.scanStruct(ft.Type, subOpt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// Unknown 返回 key 是否为未知属性，即 key 位于某个结构体绑定的属性前缀之下，但
+// 是没有和它对应的字段，这种情况通常是属性名拼写错误导致的。
+func (c *Catalog) Unknown(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key = canonicalKey(key)
+	owned := false
+	for _, r := range c.prefixes {
+		if r.MatchString(key) {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return false
+	}
+
+	for _, r := range c.patterns {
+		if r.MatchString(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookup 返回与 key 匹配的属性元数据，元数据中的属性名可以包含通配符。
+func (c *Catalog) Lookup(key string) (Meta, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key = canonicalKey(key)
+	for k, r := range c.patterns {
+		if r.MatchString(key) {
+			return *c.metas[k], true
+		}
+	}
+	return Meta{}, false
+}
+
+// keyRegexp 将包含通配符的属性名转换成正则表达式，* 匹配 map 的 key ，[*] 匹配
+// slice 和 array 的下标，末尾的 * 可以匹配多级属性名。
+func keyRegexp(key string) string {
+	s := regexp.QuoteMeta(key)
+	s = strings.ReplaceAll(s, `\[\*\]`, `\[\d+\]`)
+	if strings.HasSuffix(s, `\.\*`) {
+		s = strings.TrimSuffix(s, `\*`) + `.+`
+	}
+	return "^" + strings.ReplaceAll(s, `\*`, `[^.\[]+`)
+}
+
+// Metas 返回所有属性的元数据，按照属性名排序。
+func (c *Catalog) Metas() []Meta {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ret := make([]Meta, 0, len(c.metas))
+	for _, m := range c.metas {
+		ret = append(ret, *m)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
+
+// Defaults 返回所有具有默认值的属性组成的属性列表，可以用于生成默认的配置文件。
+// 属性名中带有 * 的 map 类型属性没有确定的 key ，因此不包含在内。
+func (c *Catalog) Defaults() *Properties {
+	p := New()
+	for _, m := range c.Metas() {
+		if m.HasDefault && !strings.Contains(m.Key, "*") {
+			p.Set(m.Key, m.Default)
+		}
+	}
+	return p
+}
+
+// JSON 返回 JSON 格式的属性元数据，可以供 IDE 等工具使用。
+func (c *Catalog) JSON() ([]byte, error) {
+	return json.MarshalIndent(c.Metas(), "", "  ")
+}
+
+// Usage 以命令行帮助信息的格式输出所有属性的元数据。
+func (c *Catalog) Usage(w io.Writer) {
+	for _, m := range c.Metas() {
+		s := fmt.Sprintf("  --%s %s", m.Key, m.Type)
+		if m.HasDefault {
+			s += fmt.Sprintf(" (default %q)", m.Default)
+		}
+		s += "\n    \t" + m.Field
+		if m.FileLine != "" {
+			s += " " + m.FileLine
+		}
+		_, _ = fmt.Fprintln(w, s)
+	}
+}
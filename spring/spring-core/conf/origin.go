@@ -0,0 +1,102 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Origin 属性值的来源。
+type Origin struct {
+	Source string `json:"source,omitempty"` // 来源，比如文件路径、env:NAME、cmd:-name 等，为空表示通过代码设置
+	Line   int    `json:"line,omitempty"`   // 属性在文件中的行号，为 0 表示未知
+	Value  string `json:"value"`            // 该来源设置的属性值
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return "<code>"
+	}
+	if o.Line > 0 {
+		return o.Source + ":" + strconv.Itoa(o.Line)
+	}
+	return o.Source
+}
+
+type setArg struct {
+	source  string
+	lines   map[string]int
+	replace bool
+}
+
+// line 返回 key 在文件中的行号，找不到 key 时依次查找上一级属性的行号。
+func (arg setArg) line(key string) int {
+	for arg.lines != nil && key != "" {
+		if n, ok := arg.lines[key]; ok {
+			return n
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return 0
+}
+
+type SetOption func(arg *setArg)
+
+// Source 为 Set 方法设置属性值的来源。
+func Source(source string) SetOption {
+	return func(arg *setArg) {
+		arg.source = source
+	}
+}
+
+// Replace 设置 Set 方法整体替换 key 对应的属性及其所有子属性，而不是按照 key
+// 逐个合并。
+func Replace() SetOption {
+	return func(arg *setArg) {
+		arg.replace = true
+	}
+}
+
+// Origins 返回 key 对应的属性值的所有来源，按照设置的先后顺序排列，最后一个是当
+// 前生效的来源，之前的都是被覆盖的来源，最多保留最近的 16 个来源。key 不存在时
+// 返回 nil 。
+func (p *Properties) Origins(key string) []Origin {
+	if n := p.lookup(strings.TrimPrefix(key, RootKey+".")); n != nil && n.hasValue {
+		return append([]Origin(nil), n.origins...)
+	}
+	return nil
+}
+
+// Merge 将 q 中的属性合并到 p 中，q 中的属性值会覆盖 p 中已有的属性值，map 按照
+// key 逐个合并，list 整体替换，并且保留属性值的来源以及是否被读取过。
+func (p *Properties) Merge(q *Properties) {
+	src := q.tree()
+	if q.u != p.u {
+		q.u.Range(func(k, v interface{}) bool {
+			p.u.Store(k, v)
+			return true
+		})
+	}
+	p.update(func(root *node) *node {
+		return root.merge(src)
+	})
+}
@@ -16,7 +16,14 @@
 
 package prop
 
-import "github.com/magiconair/properties"
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/magiconair/properties"
+)
 
 // Read 将 properties 格式的字节数组解析成 map 数据。
 func Read(b []byte) (map[string]interface{}, error) {
@@ -35,3 +42,97 @@ func Read(b []byte) (map[string]interface{}, error) {
 	}
 	return ret, nil
 }
+
+// Lines 返回 properties 格式的字节数组中每个属性所在的行号。
+func Lines(b []byte) (map[string]int, error) {
+	ret := make(map[string]int)
+	next := false // 当前行是否为上一行的续行
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimLeft(strings.TrimRight(line, "\r"), " \t\f")
+		if line == "" || (!next && (line[0] == '#' || line[0] == '!')) {
+			next = false
+			continue
+		}
+		isNext := next
+		n := len(line) - len(strings.TrimRight(line, `\`))
+		if next = n%2 == 1; isNext {
+			continue
+		}
+		if key := parseKey(line); key != "" {
+			if _, ok := ret[key]; !ok {
+				ret[key] = i + 1
+			}
+		}
+	}
+	return ret, nil
+}
+
+// parseKey 返回一行属性中的 key ，key 以第一个未转义的 = 、: 或者空白字符结束。
+func parseKey(line string) string {
+	var key strings.Builder
+	for j := 0; j < len(line); j++ {
+		c := line[j]
+		if c == '\\' && j < len(line)-1 {
+			j++
+			key.WriteByte(line[j])
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+		key.WriteByte(c)
+	}
+	return key.String()
+}
+
+// Write 将 map 数据序列化成 properties 格式的字节数组，嵌套的 map 和 slice 展开
+// 成 a.b[0] 形式的 key ，并且按照字典序排列。
+func Write(m map[string]interface{}) ([]byte, error) {
+	flat := make(map[string]string)
+	flatten("", m, flat)
+	return WriteFlat(flat)
+}
+
+// WriteFlat 将 key 为完整属性名的 map 数据序列化成 properties 格式的字节数组，
+// key 按照字典序排列。
+func WriteFlat(m map[string]string) ([]byte, error) {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	p := properties.NewProperties()
+	p.DisableExpansion = true
+	for _, k := range keys {
+		if _, _, err := p.Set(k, m[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := p.Write(&buf, properties.UTF8); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func flatten(key string, v interface{}, ret map[string]string) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, e := range x {
+			if key != "" {
+				k = key + "." + k
+			}
+			flatten(k, e, ret)
+		}
+	case []interface{}:
+		for i, e := range x {
+			flatten(fmt.Sprintf("%s[%d]", key, i), e, ret)
+		}
+	case nil:
+	default:
+		ret[key] = fmt.Sprint(x)
+	}
+}
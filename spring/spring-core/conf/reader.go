@@ -17,6 +17,14 @@
 package conf
 
 import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+
+	"github.com/go-spring/spring-core/conf/dotenv"
+	"github.com/go-spring/spring-core/conf/hcl"
+	"github.com/go-spring/spring-core/conf/ini"
+	confjson "github.com/go-spring/spring-core/conf/json"
 	"github.com/go-spring/spring-core/conf/prop"
 	"github.com/go-spring/spring-core/conf/toml"
 	"github.com/go-spring/spring-core/conf/yaml"
@@ -24,8 +32,21 @@ import (
 
 func init() {
 	NewReader(yaml.Read, ".yaml", ".yml")
-	NewReader(prop.Read, ".properties")
-	NewReader(toml.Read, ".toml")
+	NewReader(prop.Read, ".properties", ".prop")
+	NewReader(toml.Read, ".toml", ".tml")
+	NewReader(confjson.Read, ".json")
+	NewReader(ini.Read, ".ini")
+	NewReader(dotenv.Read, ".env")
+	NewReader(hcl.Read, ".hcl")
+	NewSplitter(yaml.Split, ".yaml", ".yml")
+	NewWriter(yaml.Write, ".yaml", ".yml")
+	NewFlatWriter(prop.WriteFlat, ".properties", ".prop")
+	NewWriter(toml.Write, ".toml", ".tml")
+	NewWriter(confjson.Write, ".json")
+	NewLocator(prop.Lines, ".properties", ".prop")
+	NewLocator(toml.Lines, ".toml", ".tml")
+	NewLocator(ini.Lines, ".ini")
+	NewLocator(dotenv.Lines, ".env")
 }
 
 var readers = make(map[string]Reader)
@@ -39,3 +60,61 @@ func NewReader(r Reader, ext ...string) {
 		readers[s] = r
 	}
 }
+
+var locators = make(map[string]Locator)
+
+// Locator 返回属性在文件中的行号，map 的 key 是属性名，不需要包含所有属性，找不
+// 到属性时会使用上一级属性的行号。
+type Locator func(b []byte) (map[string]int, error)
+
+// NewLocator 注册属性行号的解析器，ext 是解析器支持的文件扩展名。
+func NewLocator(l Locator, ext ...string) {
+	for _, s := range ext {
+		locators[s] = l
+	}
+}
+
+var splitters = make(map[string]Splitter)
+
+// Splitter 将包含多个文档的字节数组拆分成单个的文档，比如 YAML 中使用 --- 分隔
+// 的多个文档。
+type Splitter func(b []byte) ([][]byte, error)
+
+// NewSplitter 注册多文档的拆分器，ext 是拆分器支持的文件扩展名。
+func NewSplitter(s Splitter, ext ...string) {
+	for _, e := range ext {
+		splitters[e] = s
+	}
+}
+
+var (
+	iniSection   = regexp.MustCompile(`(?m)^\s*\[[^\]]+\]\s*$`)
+	tomlTable    = regexp.MustCompile(`(?m)^\s*\[\[?[^\[\]]+\]\]?\s*$`)
+	tomlString   = regexp.MustCompile(`(?m)^\s*[\w.\-"']+\s*=\s*\[?\s*["']`)
+	dotenvExport = regexp.MustCompile(`(?m)^\s*export\s+\S+=`)
+)
+
+// detect 根据内容推断没有扩展名的文件的格式，依次尝试 JSON 、带有 [table] 或者
+// 带引号字符串的 TOML 、YAML 、带有 [section] 的 INI 、带有 export 的 .env 以
+// 及 HCL ，都不符合时按照 properties 格式解析。TOML 必须有明显的特征才会尝试，
+// 因为 a=1.10 这样的 properties 内容也是合法的 TOML ，但是值会被解析成数字。
+func detect(b []byte) string {
+	if s := bytes.TrimSpace(b); len(s) > 0 && s[0] == '{' && json.Valid(s) {
+		return ".json"
+	}
+	for _, ext := range []string{".toml", ".yaml", ".ini", ".env", ".hcl"} {
+		if ext == ".toml" && !tomlTable.Match(b) && !tomlString.Match(b) {
+			continue
+		}
+		if ext == ".ini" && !iniSection.Match(b) {
+			continue
+		}
+		if ext == ".env" && !dotenvExport.Match(b) {
+			continue
+		}
+		if _, err := readers[ext](b); err == nil {
+			return ext
+		}
+	}
+	return ".properties"
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package remote 提供从远程服务加载属性的配置源，可以作为配置中心的接入点。
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+// FailurePolicy 首次加载远程配置失败时的处理策略。
+type FailurePolicy int
+
+const (
+	FailFast FailurePolicy = iota // 返回错误，应用启动失败
+	UseCache                      // 使用磁盘上最近一次成功加载的配置
+)
+
+type httpArg struct {
+	name     string
+	format   string
+	interval time.Duration
+	client   *http.Client
+	cache    string
+	policy   FailurePolicy
+	header   http.Header
+}
+
+type HTTPOption func(arg *httpArg)
+
+// Name 设置配置源的名称，用于记录属性值的来源，默认是 URL 。
+func Name(name string) HTTPOption {
+	return func(arg *httpArg) {
+		arg.name = name
+	}
+}
+
+// Format 设置配置的格式，支持 .json 和 .yaml ，默认根据 Content-Type 或者 URL
+// 的扩展名推断，都推断不出时按照 JSON 解析。
+func Format(format string) HTTPOption {
+	return func(arg *httpArg) {
+		arg.format = format
+	}
+}
+
+// Interval 设置轮询的时间间隔，默认是 30s 。
+func Interval(d time.Duration) HTTPOption {
+	return func(arg *httpArg) {
+		arg.interval = d
+	}
+}
+
+// Client 设置发起请求使用的 http.Client ，默认是 http.DefaultClient 。
+func Client(c *http.Client) HTTPOption {
+	return func(arg *httpArg) {
+		arg.client = c
+	}
+}
+
+// Header 为每个请求添加请求头，比如认证信息。
+func Header(key, value string) HTTPOption {
+	return func(arg *httpArg) {
+		arg.header.Add(key, value)
+	}
+}
+
+// Cache 设置缓存文件，每次成功加载后都会将属性保存到该文件，policy 决定首次加载
+// 失败时是否使用缓存文件。
+func Cache(file string, policy FailurePolicy) HTTPOption {
+	return func(arg *httpArg) {
+		arg.cache = file
+		arg.policy = policy
+	}
+}
+
+// HTTP 通过轮询 HTTP 接口获取 JSON 或者 YAML 格式的配置，支持 ETag 。
+type HTTP struct {
+	url string
+	arg httpArg
+
+	mutex sync.Mutex
+	etag  string
+	last  map[string]string
+}
+
+// NewHTTP 返回轮询 url 的配置源。
+func NewHTTP(url string, opts ...HTTPOption) *HTTP {
+	arg := httpArg{
+		name:     url,
+		interval: 30 * time.Second,
+		client:   http.DefaultClient,
+		policy:   FailFast,
+		header:   make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+	return &HTTP{url: url, arg: arg}
+}
+
+// Name 返回配置源的名称。
+func (h *HTTP) Name() string {
+	return h.arg.name
+}
+
+// Load 加载远程配置，失败时根据 FailurePolicy 返回错误或者使用缓存文件。
+func (h *HTTP) Load(ctx context.Context) (map[string]string, error) {
+	m, _, err := h.fetch(ctx)
+	if err == nil {
+		return m, nil
+	}
+	if h.arg.policy != UseCache || h.arg.cache == "" {
+		return nil, err
+	}
+	b, cacheErr := ioutil.ReadFile(h.arg.cache)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("%v and read cache error: %w", err, cacheErr)
+	}
+	c, cacheErr := conf.Read(b, ".json")
+	if cacheErr != nil {
+		return nil, fmt.Errorf("%v and read cache error: %w", err, cacheErr)
+	}
+	m = c.Dump()
+	log.Warnf("load %s error: %v, use cache %s", h.arg.name, err, h.arg.cache)
+	h.mutex.Lock()
+	h.last = m
+	h.mutex.Unlock()
+	return m, nil
+}
+
+// Watch 按照设置的时间间隔轮询远程配置，配置发生变化时发送全部属性，ctx 结束时关
+// 闭 channel 。轮询失败时只输出日志，保持当前的配置不变。
+func (h *HTTP) Watch(ctx context.Context) (<-chan map[string]string, error) {
+	ch := make(chan map[string]string)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(h.arg.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			m, changed, err := h.fetch(ctx)
+			if err != nil {
+				log.Errorf("poll %s error: %v", h.arg.name, err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// fetch 请求远程配置，返回全部属性以及属性是否发生变化，服务端返回 304 时属性没有
+// 变化。
+func (h *HTTP) fetch(ctx context.Context) (map[string]string, bool, error) {
+
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range h.arg.header {
+		req.Header[k] = v
+	}
+
+	h.mutex.Lock()
+	etag, last := h.etag, h.last
+	h.mutex.Unlock()
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := h.arg.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && last != nil {
+		return last, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("get %s error: %s", h.url, resp.Status)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	p, err := h.parse(b, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, false, fmt.Errorf("parse %s error: %w", h.url, err)
+	}
+	m := p.Dump()
+
+	h.mutex.Lock()
+	h.etag, h.last = resp.Header.Get("ETag"), m
+	h.mutex.Unlock()
+
+	if h.arg.cache != "" {
+		if err = writeCache(h.arg.cache, m); err != nil {
+			log.Errorf("write cache %s error: %v", h.arg.cache, err)
+		}
+	}
+	return m, !reflect.DeepEqual(m, last), nil
+}
+
+func (h *HTTP) parse(b []byte, contentType string) (*conf.Properties, error) {
+
+	format := h.arg.format
+	if format == "" {
+		switch {
+		case strings.Contains(contentType, "json"):
+			format = ".json"
+		case strings.Contains(contentType, "yaml"):
+			format = ".yaml"
+		default:
+			format = path.Ext(strings.SplitN(h.url, "?", 2)[0])
+		}
+	}
+
+	switch format {
+	case ".yaml", ".yml":
+		return conf.Read(b, ".yaml")
+	default:
+		// 使用 conf 的 json 读取器，数字按照原样保存，避免大整数丢失精度。
+		return conf.Read(b, ".json")
+	}
+}
+
+func writeCache(file string, m map[string]string) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0600)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-core/conf/remote"
+	"github.com/go-spring/spring-stl/assert"
+)
+
+type configServer struct {
+	mutex       sync.Mutex
+	body        string
+	etag        string
+	status      int
+	notModified int
+}
+
+func (s *configServer) set(body, etag string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.body, s.etag = body, etag
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	if r.Header.Get("If-None-Match") == s.etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	_, _ = w.Write([]byte(s.body))
+}
+
+func TestHTTP(t *testing.T) {
+
+	s := &configServer{}
+	s.set(`{"db":{"url":"a","hosts":["h1","h2"]},"id":9007199254740993}`, "v1")
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := remote.NewHTTP(server.URL+"/app.json", remote.Interval(10*time.Millisecond))
+	assert.Equal(t, h.Name(), server.URL+"/app.json")
+
+	m, err := h.Load(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, m, map[string]string{
+		"db.url":      "a",
+		"db.hosts[0]": "h1",
+		"db.hosts[1]": "h2",
+		"id":          "9007199254740993",
+	})
+
+	ch, err := h.Watch(ctx)
+	assert.Nil(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	s.set(`{"db":{"url":"b"}}`, "v2")
+
+	select {
+	case m = <-ch:
+		assert.Equal(t, m, map[string]string{"db.url": "b"})
+	case <-time.After(2 * time.Second):
+		t.Fatal("watch timeout")
+	}
+
+	s.mutex.Lock()
+	assert.True(t, s.notModified > 0)
+	s.mutex.Unlock()
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestHTTP_Yaml(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte("db:\n  url: a\n"))
+	}))
+	defer server.Close()
+
+	m, err := remote.NewHTTP(server.URL).Load(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, m, map[string]string{"db.url": "a"})
+}
+
+func TestHTTP_FailurePolicy(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "remote")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	cache := filepath.Join(dir, "cache.json")
+
+	s := &configServer{}
+	s.set(`{"db":{"url":"a"}}`, "v1")
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ctx := context.Background()
+	_, err = remote.NewHTTP(server.URL, remote.Cache(cache, remote.UseCache)).Load(ctx)
+	assert.Nil(t, err)
+
+	s.mutex.Lock()
+	s.status = http.StatusInternalServerError
+	s.mutex.Unlock()
+
+	_, err = remote.NewHTTP(server.URL, remote.Cache(cache, remote.FailFast)).Load(ctx)
+	assert.Error(t, err, "500 Internal Server Error")
+
+	m, err := remote.NewHTTP(server.URL, remote.Cache(cache, remote.UseCache)).Load(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, m, map[string]string{"db.url": "a"})
+
+	_ = os.Remove(cache)
+	_, err = remote.NewHTTP(server.URL, remote.Cache(cache, remote.UseCache)).Load(ctx)
+	assert.Error(t, err, "read cache error")
+}
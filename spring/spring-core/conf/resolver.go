@@ -0,0 +1,156 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func init() {
+	NewResolver("env", resolveEnv)
+	NewResolver("file", resolveFile)
+	NewResolver("base64", resolveBase64)
+	NewResolver("random.int", resolveRandomInt)
+	NewResolver("random.uuid", resolveRandomUUID)
+}
+
+var resolvers = struct {
+	sync.RWMutex
+	m map[string]Resolver
+}{m: make(map[string]Resolver)}
+
+// Resolver 函数形式的属性引用解析器，支持 ${name:arg} 、${name(arg)} 以及
+// ${name} 三种形式，arg 是函数的参数，比如 ${env:HOME} 中的 HOME ，以及
+// ${random.int(1,100)} 中的 1,100 。解析器返回 ErrNotExist 时会使用默认值。
+type Resolver func(arg string) (string, error)
+
+// NewResolver 注册函数形式的属性引用解析器，name 是函数名，同名的解析器会被覆盖。
+func NewResolver(name string, r Resolver) {
+	resolvers.Lock()
+	defer resolvers.Unlock()
+	resolvers.m[name] = r
+}
+
+// findResolver 返回 key 对应的解析器以及函数参数，key 不是函数形式时返回 nil 。
+func findResolver(key string) (Resolver, string) {
+	resolvers.RLock()
+	defer resolvers.RUnlock()
+
+	if i := strings.Index(key, ":"); i > 0 {
+		if r, ok := resolvers.m[key[:i]]; ok {
+			return r, key[i+1:]
+		}
+	}
+
+	if i := strings.Index(key, "("); i > 0 && strings.HasSuffix(key, ")") {
+		if r, ok := resolvers.m[key[:i]]; ok {
+			return r, key[i+1 : len(key)-1]
+		}
+	}
+
+	if r, ok := resolvers.m[key]; ok {
+		return r, ""
+	}
+	return nil, ""
+}
+
+// resolveEnv 返回环境变量的值，如 ${env:HOME} 。
+func resolveEnv(arg string) (string, error) {
+	if s, ok := os.LookupEnv(arg); ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("env %q %w", arg, ErrNotExist)
+}
+
+// resolveFile 返回文件去掉首尾空白后的内容，如 ${file:/run/secrets/password} 。
+func resolveFile(arg string) (string, error) {
+	b, err := ioutil.ReadFile(arg)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("file %q %w", arg, ErrNotExist)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// resolveBase64 返回 base64 解码后的内容，如 ${base64:aGVsbG8=} 。
+func resolveBase64(arg string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(arg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// resolveRandomInt 返回随机整数，${random.int} 的范围是 [0,MaxInt32] ，
+// ${random.int(max)} 的范围是 [0,max) ，${random.int(min,max)} 的范围是
+// [min,max) ，每次解析都会生成新的值。
+func resolveRandomInt(arg string) (string, error) {
+
+	min, max := int64(0), int64(math.MaxInt32)+1
+	if arg != "" {
+		ss := strings.Split(arg, ",")
+		if len(ss) > 2 {
+			return "", fmt.Errorf("random.int(%s) 参数错误", arg)
+		}
+		var err error
+		var n []int64
+		for _, s := range ss {
+			var i int64
+			if i, err = strconv.ParseInt(strings.TrimSpace(s), 10, 64); err != nil {
+				return "", fmt.Errorf("random.int(%s) 参数错误", arg)
+			}
+			n = append(n, i)
+		}
+		if len(n) == 1 {
+			max = n[0]
+		} else {
+			min, max = n[0], n[1]
+		}
+		if min >= max {
+			return "", fmt.Errorf("random.int(%s) 参数错误", arg)
+		}
+	}
+
+	i, err := rand.Int(rand.Reader, big.NewInt(max-min))
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(min+i.Int64(), 10), nil
+}
+
+// resolveRandomUUID 返回随机的 UUID ，如 ${random.uuid} ，每次解析都会生成新
+// 的值。
+func resolveRandomUUID(string) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -17,6 +17,8 @@
 package toml
 
 import (
+	"fmt"
+
 	"github.com/pelletier/go-toml"
 )
 
@@ -28,3 +30,80 @@ func Read(b []byte) (map[string]interface{}, error) {
 	}
 	return tree.ToMap(), nil
 }
+
+// Lines 返回 toml 格式的字节数组中每个属性所在的行号。
+func Lines(b []byte) (map[string]int, error) {
+	tree, err := toml.LoadBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]int)
+	walk(tree, "", ret)
+	return ret, nil
+}
+
+func walk(tree *toml.Tree, prefix string, lines map[string]int) {
+	for _, k := range tree.Keys() {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		path := []string{k}
+		lines[key] = tree.GetPositionPath(path).Line
+		switch v := tree.GetPath(path).(type) {
+		case *toml.Tree:
+			walk(v, key, lines)
+		case []*toml.Tree:
+			for i, t := range v {
+				walk(t, fmt.Sprintf("%s[%d]", key, i), lines)
+			}
+		}
+	}
+}
+
+// Write 将 map 数据序列化成 toml 格式的字节数组，key 按照字典序排列。toml 没有
+// 表示空值的方法，因此 list 中缺少元素时返回错误。
+func Write(m map[string]interface{}) (b []byte, err error) {
+	if err = checkNil("", m); err != nil {
+		return nil, err
+	}
+	// go-toml 遇到无法处理的值时可能 panic ，转换成 error 返回。
+	defer func() {
+		if r := recover(); r != nil {
+			b, err = nil, fmt.Errorf("toml: %v", r)
+		}
+	}()
+	tree, err := toml.TreeFromMap(m)
+	if err != nil {
+		return nil, err
+	}
+	s, err := tree.ToTomlString()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// checkNil 检查 v 中是否存在空值，key 是 v 的属性名。
+func checkNil(key string, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		return fmt.Errorf("toml: property %s is missing, toml doesn't support null", key)
+	case map[string]interface{}:
+		for k, e := range x {
+			if key != "" {
+				k = key + "." + k
+			}
+			if err := checkNil(k, e); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, e := range x {
+			if err := checkNil(fmt.Sprintf("%s[%d]", key, i), e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
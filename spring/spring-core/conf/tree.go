@@ -0,0 +1,424 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-spring/spring-stl/cast"
+)
+
+// node 属性树的结点。属性的 key 按照 . 和 [i] 拆分成路径，路径上的每一段对应一
+// 个结点，因此查找属性的时间只与 key 的层级有关。同一个结点可以同时具有属性值、
+// map 形式的子结点以及 list 形式的子结点，以兼容 a=1 和 a.b=2 同时存在的写法。
+// 属性树发布之后不再修改，修改时只复制从根结点到被修改结点路径上的结点，其他结点
+// 被新旧两棵树共享，因此并发的读取不需要加锁。
+type node struct {
+	name     string           // 属性名的实际写法
+	value    string           // 属性值
+	hasValue bool             // 是否具有属性值
+	origins  []Origin         // 属性值的来源
+	seq      uint64           // 属性值的设置顺序，越大表示越晚设置
+	children map[string]*node // map 形式的子结点，key 是属性名
+	elems    []*node          // list 形式的子结点，缺少的元素为 nil
+}
+
+// valueSeq 属性值的设置顺序的计数器。
+var valueSeq uint64
+
+// nextSeq 返回下一个属性值的设置顺序。
+func nextSeq() uint64 {
+	return atomic.AddUint64(&valueSeq, 1)
+}
+
+// segment 属性名中的一段，index 小于 0 时是 map 的 key ，否则是 list 的下标。
+type segment struct {
+	name  string
+	index int
+}
+
+// parseKey 将 a.b[0].c 形式的 key 拆分成 a 、b 、[0] 、c 四段，格式不正确的部
+// 分原样作为属性名。
+func parseKey(key string) []segment {
+	path := make([]segment, 0, strings.Count(key, ".")+strings.Count(key, "[")+1)
+	return appendKey(path, key)
+}
+
+// appendKey 将 key 拆分后追加到 path 中，查找属性时可以传入栈上的数组以避免内存
+// 分配。
+func appendKey(path []segment, key string) []segment {
+	for {
+		s := key
+		i := strings.IndexByte(key, '.')
+		if i >= 0 {
+			s, key = key[:i], key[i+1:]
+		}
+		path = appendSegment(path, s)
+		if i < 0 {
+			return path
+		}
+	}
+}
+
+// appendSegment 将 a[0][1] 形式的一段拆分后追加到 path 中。
+func appendSegment(path []segment, s string) []segment {
+	i := strings.IndexByte(s, '[')
+	if i <= 0 {
+		return append(path, segment{name: s, index: -1})
+	}
+	n := len(path)
+	path = append(path, segment{name: s[:i], index: -1})
+	for r := s[i:]; r != ""; {
+		j := strings.IndexByte(r, ']')
+		if r[0] != '[' || j < 0 {
+			return append(path[:n], segment{name: s, index: -1})
+		}
+		index, err := strconv.Atoi(r[1:j])
+		if err != nil || index < 0 {
+			return append(path[:n], segment{name: s, index: -1})
+		}
+		path = append(path, segment{index: index})
+		r = r[j+1:]
+	}
+	return path
+}
+
+// childKey 返回子结点的完整属性名。
+func childKey(key string, seg segment) string {
+	if seg.index >= 0 {
+		return key + "[" + strconv.Itoa(seg.index) + "]"
+	}
+	if key == "" {
+		return seg.name
+	}
+	return key + "." + seg.name
+}
+
+// maxKeyDepth 查找属性时栈上数组的长度，层级更深的 key 会在堆上分配。
+const maxKeyDepth = 8
+
+// find 返回 path 对应的结点，属性名需要精确匹配。create 为 true 时创建不存在的
+// 结点，否则找不到结点时返回 nil 。create 为 true 时会修改属性树，只能用于尚未发
+// 布的属性树。
+func (n *node) find(path []segment, create bool) *node {
+	for _, seg := range path {
+		if seg.index >= 0 {
+			if seg.index >= len(n.elems) {
+				if !create {
+					return nil
+				}
+				n.elems = append(n.elems, make([]*node, seg.index+1-len(n.elems))...)
+			}
+			c := n.elems[seg.index]
+			if c == nil {
+				if !create {
+					return nil
+				}
+				c = &node{}
+				n.elems[seg.index] = c
+			}
+			n = c
+			continue
+		}
+		c, ok := n.children[seg.name]
+		if !ok {
+			if !create {
+				return nil
+			}
+			if n.children == nil {
+				n.children = make(map[string]*node)
+			}
+			c = &node{name: seg.name}
+			n.children[seg.name] = c
+		}
+		n = c
+	}
+	return n
+}
+
+// relaxed 返回与 path 宽松匹配的结点以及它在属性树中的实际路径，找不到时返回
+// nil 。basePath 、base_path 以及 base-path 是相互匹配的写法，同时存在多种写法
+// 时使用最后设置的写法。宽松匹配只用于结构体字段声明的属性名，map 的 key 和
+// list 的下标仍然精确匹配。
+func (n *node) relaxed(path []segment) (*node, []segment) {
+	if len(path) == 0 {
+		return n, nil
+	}
+	seg := path[0]
+	if seg.index >= 0 {
+		if seg.index >= len(n.elems) || n.elems[seg.index] == nil {
+			return nil, nil
+		}
+		c, rest := n.elems[seg.index].relaxed(path[1:])
+		if c == nil {
+			return nil, nil
+		}
+		return c, append([]segment{seg}, rest...)
+	}
+	var (
+		found *node
+		real  []segment
+	)
+	ck := canonicalKey(seg.name)
+	for _, c := range n.sortedChildren() {
+		if canonicalKey(c.name) != ck {
+			continue
+		}
+		m, rest := c.relaxed(path[1:])
+		if m == nil || (found != nil && m.latest() <= found.latest()) {
+			continue
+		}
+		found = m
+		real = append([]segment{{name: c.name, index: -1}}, rest...)
+	}
+	return found, real
+}
+
+// latest 返回结点及其子结点中最后设置的属性值的设置顺序。
+func (n *node) latest() uint64 {
+	seq := n.seq
+	for _, c := range n.children {
+		if s := c.latest(); s > seq {
+			seq = s
+		}
+	}
+	for _, c := range n.elems {
+		if c != nil {
+			if s := c.latest(); s > seq {
+				seq = s
+			}
+		}
+	}
+	return seq
+}
+
+// empty 返回结点及其子结点是否都没有属性值。
+func (n *node) empty() bool {
+	if n.hasValue {
+		return false
+	}
+	return !n.hasChildren()
+}
+
+// hasChildren 返回结点是否存在具有属性值的子结点。
+func (n *node) hasChildren() bool {
+	for _, c := range n.children {
+		if !c.empty() {
+			return true
+		}
+	}
+	for _, c := range n.elems {
+		if c != nil && !c.empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// shallow 返回结点的浅拷贝，子结点被共享，但是保存子结点的 map 和 slice 是新
+// 的，因此可以替换拷贝的子结点而不影响原来的结点。
+func (n *node) shallow() *node {
+	c := *n
+	if n.children != nil {
+		c.children = make(map[string]*node, len(n.children))
+		for k, v := range n.children {
+			c.children[k] = v
+		}
+	}
+	if n.elems != nil {
+		c.elems = append([]*node(nil), n.elems...)
+	}
+	return &c
+}
+
+// with 返回将 fn 应用到 path 对应的结点之后的新树，只复制 path 上的结点，不存在
+// 的结点会被创建。当前结点不会被修改。
+func (n *node) with(path []segment, fn func(n *node) *node) *node {
+	if len(path) == 0 {
+		return fn(n)
+	}
+	c := n.shallow()
+	if seg := path[0]; seg.index >= 0 {
+		if seg.index >= len(c.elems) {
+			c.elems = append(c.elems, make([]*node, seg.index+1-len(c.elems))...)
+		}
+		old := c.elems[seg.index]
+		if old == nil {
+			old = &node{}
+		}
+		c.elems[seg.index] = old.with(path[1:], fn)
+	} else {
+		old, ok := c.children[seg.name]
+		if !ok {
+			old = &node{name: seg.name}
+		}
+		if c.children == nil {
+			c.children = make(map[string]*node)
+		}
+		c.children[seg.name] = old.with(path[1:], fn)
+	}
+	return c
+}
+
+// maxOrigins 每个属性最多保留的来源数量，反复修改同一个属性时只保留最近的来源，
+// 避免来源列表无限增长导致每次修改都要复制越来越长的列表。
+const maxOrigins = 16
+
+// merge 返回将 src 合并到当前结点之后的新结点，当前结点和 src 都不会被修改。map
+// 形式的子结点按照 key 逐个合并，list 形式的子结点整体替换，没有 list 的属性值
+// (比如逗号分隔的列表) 也会替换已有的 list 。
+func (n *node) merge(src *node) *node {
+	c := n.shallow()
+	if src.hasValue {
+		c.value, c.hasValue, c.seq = src.value, true, nextSeq()
+		c.origins = append(n.origins[:len(n.origins):len(n.origins)], src.origins...)
+		if len(c.origins) > maxOrigins {
+			c.origins = c.origins[len(c.origins)-maxOrigins:]
+		}
+		if src.elems == nil {
+			c.elems = nil
+		}
+	}
+	if src.elems != nil {
+		c.elems = src.elems
+	}
+	for k, sc := range src.children {
+		if c.children == nil {
+			c.children = make(map[string]*node)
+		}
+		old, ok := c.children[k]
+		if !ok {
+			c.children[k] = sc
+			continue
+		}
+		c.children[k] = old.merge(sc)
+	}
+	return c
+}
+
+// walk 按照属性名的字典序遍历所有具有属性值的结点。
+func (n *node) walk(key string, fn func(key string, n *node)) {
+	if n.hasValue {
+		fn(key, n)
+	}
+	for _, c := range n.sortedChildren() {
+		c.walk(childKey(key, segment{name: c.name, index: -1}), fn)
+	}
+	for i, c := range n.elems {
+		if c != nil {
+			c.walk(childKey(key, segment{index: i}), fn)
+		}
+	}
+}
+
+// sortedChildren 返回按照属性名排序的 map 形式的子结点。
+func (n *node) sortedChildren() []*node {
+	ret := make([]*node, 0, len(n.children))
+	for _, c := range n.children {
+		ret = append(ret, c)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].name < ret[j].name })
+	return ret
+}
+
+// build 将 val 转换成属性树，map 转换成 map 形式的子结点，slice 和 array 转换
+// 成 list 形式的子结点，其他类型转换成属性值。key 是 val 的属性名，用于记录属性
+// 值的来源。
+func build(key string, val interface{}, arg setArg) *node {
+	n := &node{}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Map:
+		n.children = make(map[string]*node)
+		for _, k := range v.MapKeys() {
+			name := cast.ToString(k.Interface())
+			subKey := childKey(key, segment{name: name, index: -1})
+			c := n.find(parseKey(name), true)
+			*c = *c.merge(build(subKey, v.MapIndex(k).Interface(), arg))
+		}
+	case reflect.Array, reflect.Slice:
+		n.elems = make([]*node, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.elems[i] = build(childKey(key, segment{index: i}), v.Index(i).Interface(), arg)
+		}
+	default:
+		s := cast.ToString(val)
+		n.value, n.hasValue, n.seq = s, true, nextSeq()
+		n.origins = []Origin{{Source: arg.source, Line: arg.line(key), Value: s}}
+	}
+	return n
+}
+
+// tree 返回结点对应的树形结构的数据，属性值为 string ，map 形式的子结点为
+// map[string]interface{} ，list 形式的子结点为 []interface{} ，缺少的元素为
+// nil 。同一个结点具有多种形式的数据时返回错误。
+func (n *node) tree(key string) (interface{}, error) {
+	var (
+		hasMap  bool
+		hasList bool
+	)
+	for _, c := range n.children {
+		if !c.empty() {
+			hasMap = true
+			break
+		}
+	}
+	for _, c := range n.elems {
+		if c != nil && !c.empty() {
+			hasList = true
+			break
+		}
+	}
+	if (n.hasValue && (hasMap || hasList)) || (hasMap && hasList) {
+		return nil, fmt.Errorf("property %s conflicts with other properties", key)
+	}
+	switch {
+	case n.hasValue:
+		return n.value, nil
+	case hasList:
+		s := make([]interface{}, len(n.elems))
+		for i, c := range n.elems {
+			if c == nil || c.empty() {
+				continue
+			}
+			v, err := c.tree(childKey(key, segment{index: i}))
+			if err != nil {
+				return nil, err
+			}
+			s[i] = v
+		}
+		return s, nil
+	default:
+		m := make(map[string]interface{})
+		for _, c := range n.sortedChildren() {
+			if c.empty() {
+				continue
+			}
+			v, err := c.tree(childKey(key, segment{name: c.name, index: -1}))
+			if err != nil {
+				return nil, err
+			}
+			m[c.name] = v
+		}
+		return m, nil
+	}
+}
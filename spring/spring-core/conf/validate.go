@@ -0,0 +1,220 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation 违反校验规则的属性。
+type Violation struct {
+	Key  string // 完整的属性名
+	Path string // 绑定对象的路径
+	Rule string // 违反的校验规则
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("property %q (%s) violates %s", v.Key, v.Path, v.Rule)
+}
+
+// ValidateError 属性校验失败的错误，包含所有违反校验规则的属性。
+type ValidateError struct {
+	Violations []Violation
+}
+
+func (e *ValidateError) Error() string {
+	ss := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		ss[i] = v.String()
+	}
+	return "validate failed: " + strings.Join(ss, "; ")
+}
+
+// rule 校验规则，如 required 、min=1 、max=65535 、regex=^\w+$ 、oneof=a b c 。
+type rule struct {
+	name string
+	arg  string
+}
+
+func (r rule) String() string {
+	if r.arg == "" {
+		return r.name
+	}
+	return r.name + "=" + r.arg
+}
+
+// parseRules 解析逗号分隔的校验规则，regex 规则会使用剩余的全部字符串，所以它
+// 只能是最后一个规则。
+func parseRules(s string) ([]rule, error) {
+	var rules []rule
+	for s != "" {
+		var r string
+		if strings.HasPrefix(s, "regex=") {
+			r, s = s, ""
+		} else if i := strings.Index(s, ","); i >= 0 {
+			r, s = s[:i], s[i+1:]
+		} else {
+			r, s = s, ""
+		}
+		ss := strings.SplitN(strings.TrimSpace(r), "=", 2)
+		switch ss[0] {
+		case "required":
+			if len(ss) > 1 {
+				return nil, errors.New("required 规则不能有参数")
+			}
+			rules = append(rules, rule{name: ss[0]})
+		case "min", "max", "regex", "oneof":
+			if len(ss) < 2 || ss[1] == "" {
+				return nil, fmt.Errorf("%s 规则缺少参数", ss[0])
+			}
+			rules = append(rules, rule{name: ss[0], arg: ss[1]})
+		default:
+			return nil, fmt.Errorf("未知的规则 %s", ss[0])
+		}
+	}
+	return rules, nil
+}
+
+// validate 使用 opt.validate 中的规则校验绑定后的值，违反规则时将违规信息保存
+// 到 opt.errs 中，规则本身有错误时返回 error 。
+func validate(v reflect.Value, opt bindOption) error {
+
+	if opt.validate == "" || opt.errs == nil {
+		return nil
+	}
+
+	rules, err := parseRules(opt.validate)
+	if err != nil {
+		return fmt.Errorf("%s 校验规则 %s 语法错误: %w", opt.path, opt.validate, err)
+	}
+
+	key := strings.TrimPrefix(opt.key, RootKey+".")
+	for _, r := range rules {
+		ok, err := r.check(v)
+		if err != nil {
+			return fmt.Errorf("%s 校验规则 %s 错误: %w", opt.path, r, err)
+		}
+		if !ok {
+			opt.errs.Violations = append(opt.errs.Violations, Violation{
+				Key:  key,
+				Path: opt.path,
+				Rule: r.String(),
+			})
+		}
+	}
+	return nil
+}
+
+func (r rule) check(v reflect.Value) (bool, error) {
+	switch r.name {
+	case "required":
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			return v.Len() > 0, nil
+		}
+		return !v.IsZero(), nil
+	case "min", "max":
+		n, err := compare(v, r.arg)
+		if err != nil {
+			return false, err
+		}
+		if r.name == "min" {
+			return n >= 0, nil
+		}
+		return n <= 0, nil
+	case "regex":
+		exp, err := regexp.Compile(r.arg)
+		if err != nil {
+			return false, err
+		}
+		return exp.MatchString(fmt.Sprint(v.Interface())), nil
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, a := range strings.Fields(r.arg) {
+			if s == a {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("未知的规则 %s", r.name)
+}
+
+// compare 比较 v 和 arg 的大小，字符串、slice 、map 以及 array 比较的是长度，
+// time.Duration 类型的 arg 可以是 1s 这样的格式。
+func compare(v reflect.Value, arg string) (int, error) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt64(int64(v.Len()), int64(n)), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			if d, err := time.ParseDuration(arg); err == nil {
+				return compareInt64(v.Int(), int64(d)), nil
+			}
+		}
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt64(v.Int(), n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		switch u := v.Uint(); {
+		case u < n:
+			return -1, nil
+		case u > n:
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, err
+		}
+		switch x := v.Float(); {
+		case x < f:
+			return -1, nil
+		case x > f:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("不支持的类型 %s", v.Type())
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
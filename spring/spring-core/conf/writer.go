@@ -0,0 +1,87 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"io"
+)
+
+var (
+	writers     = make(map[string]Writer)
+	flatWriters = make(map[string]FlatWriter)
+)
+
+// Writer 属性列表序列化器，将树形结构的 map 数据序列化成字节数组，要求输出的内容
+// 是确定的，即相同的数据总是得到相同的结果。
+type Writer func(m map[string]interface{}) ([]byte, error)
+
+// NewWriter 注册属性列表序列化器，ext 是序列化器支持的文件扩展名。
+func NewWriter(w Writer, ext ...string) {
+	for _, s := range ext {
+		writers[s] = w
+	}
+}
+
+// FlatWriter 扁平格式的属性列表序列化器，将 key 为完整属性名的 map 数据序列化成
+// 字节数组，因此同一个属性既有属性值又有子属性时也能够输出。要求输出的内容是确定的。
+type FlatWriter func(m map[string]string) ([]byte, error)
+
+// NewFlatWriter 注册扁平格式的属性列表序列化器，ext 是序列化器支持的文件扩展名，
+// 同一个扩展名优先使用扁平格式的序列化器。
+func NewFlatWriter(w FlatWriter, ext ...string) {
+	for _, s := range ext {
+		flatWriters[s] = w
+	}
+}
+
+// Write 将属性列表按照 ext 对应的格式写入 w ，ext 是文件扩展名，如 .yaml、.toml
+// 等。加密的属性值保持 ENC(...) 形式，属性值中的引用也不会被解析。
+func (p *Properties) Write(w io.Writer, ext string) error {
+	b, err := p.marshal(ext)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (p *Properties) marshal(ext string) ([]byte, error) {
+	if fn, ok := flatWriters[ext]; ok {
+		return fn(p.Dump())
+	}
+	fn, ok := writers[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file type %s", ext)
+	}
+	m, err := p.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return fn(m)
+}
+
+// Tree 将属性列表转换成树形结构，a.b 形式的 key 转换成嵌套的 map ，a[0] 形式
+// 的 key 转换成 slice ，slice 中缺少的元素为 nil 。同一个 key 既有属性值又有子
+// 属性时返回错误。
+func (p *Properties) Tree() (map[string]interface{}, error) {
+	v, err := p.tree().tree("")
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]interface{}), nil
+}
@@ -17,6 +17,9 @@
 package yaml
 
 import (
+	"bytes"
+	"io"
+
 	"gopkg.in/yaml.v2"
 )
 
@@ -29,3 +32,33 @@ func Read(b []byte) (map[string]interface{}, error) {
 	}
 	return m, nil
 }
+
+// Split 将使用 --- 分隔的多个 yaml 文档拆分成单个的文档。文档的边界由 yaml 解码
+// 器决定，因此 | 和 > 块中以 --- 开头的行不会被当作分隔符。拆分后的每个文档会被重
+// 新序列化，属性的值和 Read 解析的结果保持一致。
+func Split(b []byte) ([][]byte, error) {
+	var docs [][]byte
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc yaml.MapSlice
+		if err := d.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		s, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, s)
+	}
+	if len(docs) == 0 {
+		return [][]byte{b}, nil
+	}
+	return docs, nil
+}
+
+// Write 将 map 数据序列化成 yaml 格式的字节数组，map 的 key 按照字典序排列。
+func Write(m map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(m)
+}
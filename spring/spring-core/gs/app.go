@@ -19,6 +19,7 @@ package gs
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -26,12 +27,16 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"sort"
 	"strings"
 	"syscall"
 
 	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/conf/aesgcm"
 	"github.com/go-spring/spring-core/grpc"
 	"github.com/go-spring/spring-core/gs/arg"
+	"github.com/go-spring/spring-core/gs/cond"
 	"github.com/go-spring/spring-core/gs/environ"
 	"github.com/go-spring/spring-core/log"
 	"github.com/go-spring/spring-core/mq"
@@ -40,7 +45,23 @@ import (
 	"github.com/go-spring/spring-stl/util"
 )
 
-type AppContext interface{ Pandora }
+// AppContext 应用上下文，除了 Pandora 提供的方法，还可以获取命令行中不属于属性
+// 的位置参数，比如 app --debug=true -- a b 中的 a 和 b 。和 Pandora 一样，它由
+// 框架实现并且可能增加新的方法。
+type AppContext interface {
+	Pandora
+	Args() []string
+}
+
+type appContext struct {
+	*pandora
+	args []string
+}
+
+// Args 返回命令行中的位置参数。
+func (ctx *appContext) Args() []string {
+	return ctx.args
+}
 
 // AppRunner 导出 appRunner 类型
 var AppRunner = (*appRunner)(nil)
@@ -74,8 +95,23 @@ type App struct {
 
 	exitChan chan struct{}
 
+	// 保存进程 ID 的文件
+	pidFile string
+
 	// 属性列表解析完成后的回调
 	mapOfOnProperty map[string]interface{}
+
+	// 收到 SIGHUP 信号后的回调
+	reloadHooks []func(ctx AppContext) error
+
+	// 配置文件重新加载后的回调
+	changeHooks []func(keys []string)
+
+	// 配置源，比如配置中心
+	sources []ConfigSource
+
+	// 嵌入式配置目录
+	configFS []configFS
 }
 
 type Consumers struct {
@@ -119,12 +155,17 @@ func (app *App) Run() error {
 	}()
 
 	if err := app.start(); err != nil {
+		app.removePidFile()
+		if err == flag.ErrHelp {
+			return nil
+		}
 		return err
 	}
 
 	<-app.exitChan
 
 	app.c.Close()
+	app.removePidFile()
 	log.Info("application exited")
 	return nil
 }
@@ -134,11 +175,15 @@ func (app *App) start() error {
 	app.Object(app.router)
 	app.Object(app.consumers)
 
-	e := newEnvironment()
+	e := newEnvironment(app.Catalog())
 	if err := e.prepare(); err != nil {
 		return err
 	}
 
+	if err := setDecryptor(e); err != nil {
+		return err
+	}
+
 	configLocations := func() []string {
 		s := e.Get(environ.SpringConfigLocations, conf.Def("config/"))
 		return strings.Split(cast.ToString(s), ",")
@@ -150,31 +195,78 @@ func (app *App) start() error {
 	}
 
 	configExtensions := func() []string {
-		extensions := ".properties,.prop,.yaml,.yml,.toml,.tml"
+		extensions := ".properties,.prop,.yaml,.yml,.toml,.tml,.json,.ini,.env,.hcl"
 		s := e.Get(environ.SpringConfigExtensions, conf.Def(extensions))
 		return strings.Split(cast.ToString(s), ",")
 	}()
 
-	profile := cast.ToString(e.Get(environ.SpringProfilesActive))
-	p, err := app.profile(configLocations, configExtensions, profile)
+	files := make(fileSet)
+	p, err := app.profile(e, configLocations, configExtensions, files)
 	if err != nil {
 		return err
 	}
 
-	// 保存从配置文件加载的属性
+	// 加载之后立即记录文件的状态，启动期间发生的修改也能被监视配置文件的协程发现。
+	stamps := stampFiles(files)
+
+	// 来自配置文件、GS_ 环境变量以及命令行参数的属性，启动完成后检查它们是否被使用。
+	keys := make(map[string]struct{})
 	for _, k := range p.Keys() {
-		app.c.p.Set(k, p.Get(k))
+		keys[k] = struct{}{}
+	}
+	for _, k := range e.keys {
+		keys[k] = struct{}{}
+	}
+
+	// 属性按照优先级从低到高分层保存，依次是通过 Property 方法设置的属性、配置文件
+	// 的属性、配置源的属性以及环境变量和命令行参数的属性，任何一层发生变化后重新合并。
+	base := conf.New()
+	base.Merge(app.c.p)
+	layers := &configLayers{target: app.c.p}
+	layers.layers = append(layers.layers, base, p)
+
+	for _, s := range app.sources {
+		m, err := s.Load(app.c.ctx)
+		if err != nil {
+			return fmt.Errorf("load config source %s error: %w", s.Name(), err)
+		}
+		layers.layers = append(layers.layers, sourceProperties(s, m))
+	}
+	layers.layers = append(layers.layers, e.p)
+
+	// 保存从配置文件、配置源、环境变量和命令行解析的属性
+	for _, layer := range layers.layers[1:] {
+		app.c.p.Merge(layer)
+	}
+	layers.merged = app.c.p.Snapshot()
+
+	// 命令行参数包含 --help 时输出所有属性的帮助信息然后退出。
+	if e.p.Get("help") != nil {
+		app.printUsage()
+		return flag.ErrHelp
+	}
+
+	if s := cast.ToString(app.c.p.Get(environ.SpringPidFile)); s != "" {
+		if err = writePidFile(s); err != nil {
+			return err
+		}
+		app.pidFile = s
+	}
+
+	if err = app.openLogFile(app.c.p); err != nil {
+		return err
 	}
 
-	// 保存从环境变量和命令行解析的属性
-	for _, k := range e.p.Keys() {
-		app.c.p.Set(k, e.p.Get(k))
+	if cast.ToBool(app.c.p.Get(environ.SpringConfigStrict)) {
+		if unknown := app.unknownKeys(keys); len(unknown) > 0 {
+			return fmt.Errorf("unknown properties %s", strings.Join(unknown, ", "))
+		}
 	}
 
 	for key, f := range app.mapOfOnProperty {
 		t := reflect.TypeOf(f)
 		in := reflect.New(t.In(0)).Elem()
-		err = app.c.p.Bind(in, conf.Key(key))
+		err = app.c.p.Bind(in, conf.Key(key), conf.FileLine(fileLine(f)))
 		if err != nil {
 			return err
 		}
@@ -185,7 +277,8 @@ func (app *App) start() error {
 		return err
 	}
 
-	ctx := &pandora{app.c}
+	ctx := &appContext{pandora: &pandora{app.c}, args: e.args}
+	app.handleSignals(ctx)
 
 	var runners []appRunner
 	if err = ctx.Get(&runners); err != nil {
@@ -217,10 +310,126 @@ func (app *App) start() error {
 		}
 	})
 
+	for _, k := range app.unusedKeys(keys) {
+		log.Warnf("property %s is loaded but never used", k)
+	}
+
+	if cast.ToBool(app.c.p.Get(environ.SpringConfigWatch)) {
+		s := app.c.p.Get(environ.SpringConfigWatchInterval, conf.Def("5s"))
+		interval, err := cast.ToDurationE(s)
+		if err != nil {
+			return err
+		}
+		w := newConfigWatcher(interval, stamps, func() (fileSet, error) {
+			files := make(fileSet)
+			p, err := app.profile(e, configLocations, configExtensions, files)
+			if err != nil {
+				return files, err
+			}
+			layers.update(1, p, app.onPropertyChange)
+			return files, nil
+		})
+		app.Go(w.watch)
+	}
+
+	for i, s := range app.sources {
+		ch, err := s.Watch(app.c.ctx)
+		if err != nil {
+			return fmt.Errorf("watch config source %s error: %w", s.Name(), err)
+		}
+		i, s := i+2, s
+		app.Go(func(ctx context.Context) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case m, ok := <-ch:
+					if !ok {
+						return
+					}
+					layers.update(i, sourceProperties(s, m), app.onPropertyChange)
+				}
+			}
+		})
+	}
+
 	log.Info("application started successfully")
 	return err
 }
 
+// unknownKeys 返回 keys 中的未知属性，即位于结构体绑定的属性前缀之下但是没有对应
+// 字段的属性，通常是属性名拼写错误导致的。
+func (app *App) unknownKeys(keys map[string]struct{}) []string {
+	catalog := app.Catalog()
+	var ret []string
+	for k := range keys {
+		if catalog.Unknown(k) {
+			ret = append(ret, k)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// unusedKeys 返回 keys 中在启动过程中没有被 Bind 、Resolve 、Prop 或者条件读取
+// 过的属性。
+func (app *App) unusedKeys(keys map[string]struct{}) []string {
+	var ret []string
+	for k := range keys {
+		if !app.c.p.Used(k) {
+			ret = append(ret, k)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Catalog 返回属性元数据的目录，包括框架自身绑定的 logging.file 等属性、通过
+// OnProperty 注册的回调所绑定的属性以及 bean 在属性绑定时涉及的属性。
+func (app *App) Catalog() *conf.Catalog {
+	catalog := app.c.Catalog()
+	_ = catalog.Scan(reflect.TypeOf(loggingFile{}), "${"+environ.LoggingFile+"}", fileLine((*App).openLogFile))
+	for key, f := range app.mapOfOnProperty {
+		t := reflect.TypeOf(f).In(0)
+		_ = catalog.Scan(t, "${"+key+"}", fileLine(f))
+	}
+	return catalog
+}
+
+// printUsage 以命令行帮助信息的格式输出所有属性的元数据。
+func (app *App) printUsage() {
+	fmt.Printf("Usage: %s [--key=value ...] [--] [args ...]\n", filepath.Base(os.Args[0]))
+	app.Catalog().Usage(os.Stdout)
+}
+
+// fileLine 返回函数定义的代码位置。
+func fileLine(fn interface{}) string {
+	file, line, _ := util.FileLine(fn)
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// setDecryptor 设置了密钥时使用 AES-GCM 算法解密 ENC(...) 形式的属性值，密钥
+// 来自 SPRING_CONFIG_ENCRYPT_KEY 环境变量或者 spring.config.encrypt.key-file
+// 指定的文件。
+func setDecryptor(e *environment) error {
+	var (
+		c   *aesgcm.Cipher
+		err error
+	)
+	if _, ok := os.LookupEnv(environ.SpringConfigEncryptKey); ok {
+		c, err = aesgcm.NewFromEnv(environ.SpringConfigEncryptKey)
+	} else if file := cast.ToString(e.Get(environ.SpringConfigEncryptKeyFile)); file != "" {
+		c, err = aesgcm.NewFromFile(file)
+	} else {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load encrypt key error: %w", err)
+	}
+	conf.SetDecryptor(c)
+	return nil
+}
+
 func (app *App) getBanner(configLocations []string) string {
 	if app.banner != "" {
 		return app.banner
@@ -234,31 +443,68 @@ func (app *App) getBanner(configLocations []string) string {
 	return DefaultBanner
 }
 
-func (app *App) profile(locations []string, extensions []string, profile string) (*conf.Properties, error) {
+// configLoader 加载配置文件以及它们导入的文件和目录。
+type configLoader struct {
+	profiles []string // 激活的 profile
+	files    fileSet  // 加载过程中涉及的文件和目录，包括不存在的候选文件
+}
+
+// profile 加载默认的以及所有激活的 profile 对应的配置文件，排在后面的 profile 优
+// 先级更高。激活的 profile 以及 profile 组可以来自环境变量和命令行参数，也可以来
+// 自默认的配置文件，所以默认的配置文件需要先加载一遍。
+func (app *App) profile(e *environment, locations []string, extensions []string, files fileSet) (*conf.Properties, error) {
 
 	p := conf.New()
-	if err := app.loadConfigFile(p, locations, extensions, ""); err != nil {
+	l := &configLoader{}
+	if err := app.loadConfig(p, locations, extensions, []string{""}, l); err != nil {
 		return nil, err
 	}
 
-	if profile != "" {
-		if err := app.loadConfigFile(p, locations, extensions, profile); err != nil {
-			return nil, err
+	l = &configLoader{files: files}
+	l.profiles = cond.Profiles(func(key string) interface{} {
+		if v := e.Get(key); v != nil {
+			return v
 		}
+		return p.Get(key)
+	})
+
+	p = conf.New()
+	profiles := append([]string{""}, l.profiles...)
+	if err := app.loadConfig(p, locations, extensions, profiles, l); err != nil {
+		return nil, err
 	}
 	return p, nil
 }
 
-func (app *App) loadConfigFile(p *conf.Properties, locations []string, extensions []string, profile string) error {
+// loadConfig 依次加载 profiles 对应的配置文件，空字符串表示默认的配置文件。通过
+// ConfigFS 注册的嵌入式配置的优先级低于所有的配置文件，因此先加载。
+func (app *App) loadConfig(p *conf.Properties, locations []string, extensions []string, profiles []string, l *configLoader) error {
+	for _, profile := range profiles {
+		if err := app.loadConfigFS(p, extensions, profile, l); err != nil {
+			return err
+		}
+	}
+	for _, profile := range profiles {
+		if err := app.loadConfigFile(p, locations, extensions, profile, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	filename := "application"
+// configFileName 返回 profile 对应的配置文件名，不包含扩展名。
+func configFileName(profile string) string {
 	if len(profile) > 0 {
-		filename += "-" + profile
+		return "application-" + profile
 	}
+	return "application"
+}
 
+func (app *App) loadConfigFile(p *conf.Properties, locations []string, extensions []string, profile string, l *configLoader) error {
+	filename := configFileName(profile)
 	for _, loc := range locations {
 		for _, ext := range extensions {
-			err := p.Load(filepath.Join(loc, filename+ext))
+			err := l.importConfig(p, filepath.Join(loc, filename+ext), nil)
 			if err != nil && !os.IsNotExist(err) {
 				return err
 			}
@@ -267,6 +513,228 @@ func (app *App) loadConfigFile(p *conf.Properties, locations []string, extension
 	return nil
 }
 
+// configFS 通过 ConfigFS 注册的嵌入式配置目录，类似于 Java 的 classpath ，可以
+// 用于在 starter 中提供默认的配置文件。
+type configFS struct {
+	dir  string                            // 配置文件所在的目录
+	read func(file string) ([]byte, error) // 读取配置文件的内容
+}
+
+// loadConfigFS 加载嵌入式配置目录中 profile 对应的配置文件，先注册的目录优先级
+// 更低。嵌入式配置文件不支持 spring.config.import 。
+func (app *App) loadConfigFS(p *conf.Properties, extensions []string, profile string, l *configLoader) error {
+	filename := configFileName(profile)
+	for _, f := range app.configFS {
+		for _, ext := range extensions {
+			file := path.Join(f.dir, filename+ext)
+			b, err := f.read(file)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return err
+			}
+			docs, err := conf.ReadDocuments(b, ext, conf.Source("embed:"+file))
+			if err != nil {
+				return fmt.Errorf("embed:%s error: %w", file, err)
+			}
+			p.Merge(l.merge(docs))
+		}
+	}
+	return nil
+}
+
+// importConfig 加载配置文件 file 以及它通过 spring.config.import 导入的文件或
+// 目录，导入项可以使用 optional: 前缀表示不存在时忽略，相对路径相对于 file 所在的
+// 目录。导入是递归进行的，导入的属性覆盖 file 中的属性，排在后面的导入项覆盖排在前
+// 面的导入项。chain 是当前的导入链，用于检测循环导入。YAML 文件中使用 --- 分隔
+// 的文档可以通过 spring.config.activate.on-profile 设置生效的 profile 。
+func (l *configLoader) importConfig(p *conf.Properties, file string, chain []string) error {
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	l.files.add(abs)
+	for _, s := range chain {
+		if s == abs {
+			chain = append(chain, abs)
+			return fmt.Errorf("%s cycle %s", environ.SpringConfigImport, strings.Join(chain, " -> "))
+		}
+	}
+	chain = append(chain[:len(chain):len(chain)], abs)
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return l.loadConfigTree(p, file, "")
+	}
+
+	docs, err := conf.LoadDocuments(file)
+	if err != nil {
+		return err
+	}
+
+	q := l.merge(docs)
+	imports := configImports(q)
+	p.Merge(q)
+
+	for _, s := range imports {
+		optional := strings.HasPrefix(s, "optional:")
+		s = strings.TrimPrefix(s, "optional:")
+		if !filepath.IsAbs(s) {
+			s = filepath.Join(filepath.Dir(file), s)
+		}
+		err = l.importConfig(p, s, chain)
+		if err == nil || (optional && os.IsNotExist(err)) {
+			continue
+		}
+		return fmt.Errorf("%s import %s error: %w", file, s, err)
+	}
+	return nil
+}
+
+// merge 合并所有生效的文档，排在后面的文档优先级更高。
+func (l *configLoader) merge(docs []*conf.Properties) *conf.Properties {
+	q := conf.New()
+	for _, doc := range docs {
+		if l.activated(doc) {
+			q.Merge(doc)
+		}
+	}
+	return q
+}
+
+// activated 返回文档是否生效，没有设置 spring.config.activate.on-profile 的文档
+// 总是生效，否则需要匹配任何一个激活的 profile 。
+func (l *configLoader) activated(doc *conf.Properties) bool {
+	v := doc.Get(environ.SpringConfigActivateOnProfile)
+	if v == nil {
+		return true
+	}
+	for _, s := range strings.Split(cast.ToString(v), ",") {
+		for _, profile := range l.profiles {
+			if strings.TrimSpace(s) == profile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configImports 返回 spring.config.import 的值，支持逗号分隔的字符串或者列表。
+func configImports(p *conf.Properties) []string {
+	var ret []string
+	if v := p.Get(environ.SpringConfigImport); v != nil {
+		for _, s := range strings.Split(v.(string), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	for i := 0; ; i++ {
+		v := p.Get(fmt.Sprintf("%s[%d]", environ.SpringConfigImport, i))
+		if v == nil {
+			return ret
+		}
+		if s := strings.TrimSpace(v.(string)); s != "" {
+			ret = append(ret, s)
+		}
+	}
+}
+
+// loadConfigTree 加载目录形式的配置，比如 Kubernetes 挂载的 ConfigMap 或者
+// Secret，文件名是属性名，文件内容是属性值，子目录的名称作为属性名的前缀，忽略以
+// . 开头的文件和目录。
+func (l *configLoader) loadConfigTree(p *conf.Properties, dir string, prefix string) error {
+	l.files.add(dir)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		file := filepath.Join(dir, info.Name())
+		key := info.Name()
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		fi, err := os.Stat(file) // 跟随符号链接
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if err = l.loadConfigTree(p, file, key); err != nil {
+				return err
+			}
+			continue
+		}
+		l.files.add(file)
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		s := strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+		p.Set(key, s, conf.Source(file))
+	}
+	return nil
+}
+
+func (app *App) removePidFile() {
+	if app.pidFile != "" {
+		if err := removePidFile(app.pidFile); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// handleSignals 处理 SIGHUP 和 SIGUSR1 信号，SIGHUP 信号触发通过 OnReload 注
+// 册的回调，只有存在回调时才会监听该信号，SIGUSR1 信号将所有 goroutine 的调用栈
+// 以及所有 bean 的状态输出到日志。
+func (app *App) handleSignals(ctx AppContext) {
+
+	ch := make(chan os.Signal, 1)
+	notifySignals(ch, len(app.reloadHooks) > 0)
+
+	app.Go(func(c context.Context) {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-c.Done():
+				return
+			case sig := <-ch:
+				app.handleSignal(ctx, sig)
+			}
+		}
+	})
+}
+
+// handleSignal 处理收到的 sig 信号。
+func (app *App) handleSignal(ctx AppContext, sig os.Signal) {
+	switch {
+	case isReloadSignal(sig):
+		log.Infof("received signal %v, reloading", sig)
+		for _, fn := range app.reloadHooks {
+			if err := fn(ctx); err != nil {
+				log.Errorf("reload error: %v", err)
+			}
+		}
+	case isDumpSignal(sig):
+		log.Infof("received signal %v, goroutines:\n%s", sig, dumpGoroutines())
+		log.Infof("received signal %v, beans:\n%s", sig, app.c.dumpBeans())
+	}
+}
+
+// OnReload 注册收到 SIGHUP 信号后执行的回调，回调按照注册的顺序执行。
+func (app *App) OnReload(fn func(ctx AppContext) error) {
+	app.reloadHooks = append(app.reloadHooks, fn)
+}
+
 // ShutDown 关闭执行器
 func (app *App) ShutDown(err error) {
 	log.Infof("program will exit %s", err.Error())
@@ -278,7 +746,55 @@ func (app *App) ShutDown(err error) {
 	}
 }
 
-// OnProperty 当 key 对应的属性值准备好后发送一个通知。
+// AddConfigSource 添加配置源，配置源的属性优先级高于配置文件，低于环境变量和命令
+// 行参数，配置源发生变化后会重新加载属性。
+func (app *App) AddConfigSource(s ConfigSource) {
+	app.sources = append(app.sources, s)
+}
+
+// OnPropertyChange 注册配置文件或者配置源重新加载后执行的回调，keys 是新增、删除
+// 以及值发生变化的属性名，只有开启 spring.config.watch 时才会重新加载配置文件。
+func (app *App) OnPropertyChange(fn func(keys []string)) {
+	app.changeHooks = append(app.changeHooks, fn)
+}
+
+// onPropertyChange 重新绑定与 keys 相关的 OnProperty 回调，然后执行通过
+// OnPropertyChange 注册的回调。
+func (app *App) onPropertyChange(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	log.Infof("properties changed: %s", strings.Join(keys, ", "))
+	for key, f := range app.mapOfOnProperty {
+		if !containsKey(keys, key) {
+			continue
+		}
+		t := reflect.TypeOf(f)
+		in := reflect.New(t.In(0)).Elem()
+		err := app.c.p.Bind(in, conf.Key(key), conf.FileLine(fileLine(f)))
+		if err != nil {
+			log.Errorf("bind property %s error: %v", key, err)
+			continue
+		}
+		reflect.ValueOf(f).Call([]reflect.Value{in})
+	}
+	for _, fn := range app.changeHooks {
+		fn(keys)
+	}
+}
+
+// containsKey 返回 keys 中是否存在 key 或者 key 的子属性。
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key || strings.HasPrefix(k, key+".") || strings.HasPrefix(k, key+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// OnProperty 当 key 对应的属性值准备好后发送一个通知，开启 spring.config.watch
+// 时属性值发生变化后会再次通知。
 func (app *App) OnProperty(key string, fn interface{}) {
 	t := reflect.TypeOf(fn)
 	if t.Kind() != reflect.Func {
@@ -299,6 +815,12 @@ func (app *App) Property(key string, value interface{}) {
 	app.c.Property(key, value)
 }
 
+// Convert 注册只对当前应用有效的类型转换器，转换器的函数原型为
+// func(string)(type,error) 。
+func (app *App) Convert(fn interface{}) {
+	app.c.Convert(fn)
+}
+
 // Object 注册对象形式的 bean ，需要注意的是该方法在注入开始后就不能再调用了。
 func (app *App) Object(i interface{}) *BeanDefinition {
 	return app.c.register(NewBean(reflect.ValueOf(i)))
@@ -309,6 +831,14 @@ func (app *App) Provide(ctor interface{}, args ...arg.Arg) *BeanDefinition {
 	return app.c.register(NewBean(ctor, args...))
 }
 
+// ProvideForEach 为 key 对应的 map 属性的每个条目注册一个构造函数形式的 bean ，
+// bean 的名称是条目的 key ，构造函数的属性绑定以 key.name 为前缀。需要注意的是
+// 该方法在注入开始后就不能再调用了。
+func (app *App) ProvideForEach(key string, ctor interface{}, args ...arg.Arg) {
+	_, file, line, _ := runtime.Caller(1)
+	app.c.provideForEach(forEachBean{key: key, ctor: ctor, args: args, file: file, line: line})
+}
+
 // Go 创建安全可等待的 goroutine，fn 要求的 ctx 对象由 IoC 容器提供，当 IoC 容
 // 器关闭时 ctx会 发出 Done 信号， fn 在接收到此信号后应当立即退出。
 func (app *App) Go(fn func(ctx context.Context)) {
@@ -17,36 +17,70 @@
 package gs_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/conf/aesgcm"
 	"github.com/go-spring/spring-core/gs"
 	"github.com/go-spring/spring-core/gs/environ"
+	"github.com/go-spring/spring-core/log"
 	"github.com/go-spring/spring-stl/assert"
 )
 
-func startApplication(cfgLocation string) (*gs.App, gs.Pandora) {
+// startedEvent 在应用启动完成时发出通知，测试代码据此等待应用启动。
+type startedEvent struct {
+	ch chan gs.AppContext
+}
 
-	app := gs.NewApp()
-	gs.Setenv("SPRING_BANNER_VISIBLE", true)
-	gs.Setenv("SPRING_CONFIG_LOCATION", cfgLocation)
+func (e *startedEvent) OnStartApp(ctx gs.AppContext) { e.ch <- ctx }
+
+func (e *startedEvent) OnStopApp(ctx gs.AppContext) {}
+
+// runApp 运行应用并等待应用启动完成，返回的 stop 函数关闭应用并等待 Run 方法返
+// 回，因此测试之间不会相互影响。容器刷新后只有开启 enable-pandora 才能获取到应
+// 用事件的 bean ，因此这里总是开启该属性。
+func runApp(t *testing.T, app *gs.App) (gs.AppContext, func()) {
+	t.Helper()
+
+	e := &startedEvent{ch: make(chan gs.AppContext, 1)}
 	app.Property(environ.EnablePandora, true)
+	app.Object(e).Export(gs.AppEvent)
 
-	var p gs.Pandora
-	type PandoraAware struct{}
-	app.Provide(func(b gs.Pandora) PandoraAware {
-		p = b
-		return PandoraAware{}
-	})
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
 
-	go app.Run()
-	time.Sleep(100 * time.Millisecond)
-	return app, p
+	select {
+	case ctx := <-e.ch:
+		return ctx, func() {
+			app.ShutDown(errors.New("run test end"))
+			assert.Nil(t, <-done)
+		}
+	case err := <-done:
+		t.Fatalf("app exited before startup: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait app startup timeout")
+	}
+	return nil, nil
+}
+
+func startApplication(t *testing.T, cfgLocation string) (gs.AppContext, func()) {
+	app := gs.NewApp()
+	gs.Setenv("SPRING_BANNER_VISIBLE", true)
+	gs.Setenv("SPRING_CONFIG_LOCATION", cfgLocation)
+	return runApp(t, app)
 }
 
 func TestConfig(t *testing.T) {
@@ -54,16 +88,18 @@ func TestConfig(t *testing.T) {
 	t.Run("config via env", func(t *testing.T) {
 		os.Clearenv()
 		gs.Setenv("GS_SPRING_PROFILES_ACTIVE", "dev")
-		app, p := startApplication("testdata/config/")
-		defer app.ShutDown(errors.New("run test end"))
+		p, stop := startApplication(t, "testdata/config/")
+		defer stop()
 		assert.Equal(t, p.Prop(environ.SpringProfilesActive), "dev")
+		origins := p.Origins(environ.SpringProfilesActive)
+		assert.Equal(t, origins[len(origins)-1].Source, "env:GS_SPRING_PROFILES_ACTIVE")
 	})
 
 	t.Run("config via env 2", func(t *testing.T) {
 		os.Clearenv()
 		gs.Setenv("GS_SPRING_PROFILES_ACTIVE", "dev")
-		app, p := startApplication("testdata/config/")
-		defer app.ShutDown(errors.New("run test end"))
+		p, stop := startApplication(t, "testdata/config/")
+		defer stop()
 		assert.Equal(t, p.Prop(environ.SpringProfilesActive), "dev")
 	})
 
@@ -71,8 +107,8 @@ func TestConfig(t *testing.T) {
 
 		os.Clearenv()
 		gs.Setenv("GS_SPRING_PROFILES_ACTIVE", "dev")
-		app, p := startApplication("testdata/config/")
-		defer app.ShutDown(errors.New("run test end"))
+		p, stop := startApplication(t, "testdata/config/")
+		defer stop()
 		assert.Equal(t, p.Prop(environ.SpringProfilesActive), "dev")
 
 		var m map[string]string
@@ -83,6 +119,191 @@ func TestConfig(t *testing.T) {
 	})
 }
 
+func TestPidFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "app.pid")
+	pid := strconv.Itoa(os.Getpid())
+
+	t.Run("write and remove", func(t *testing.T) {
+		err := gs.WritePidFile(file)
+		assert.Nil(t, err)
+		b, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, strings.TrimSpace(string(b)), pid)
+
+		err = gs.RemovePidFile(file)
+		assert.Nil(t, err)
+		_, err = os.Stat(file)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("already running", func(t *testing.T) {
+		ppid := strconv.Itoa(os.Getppid())
+		err := ioutil.WriteFile(file, []byte(ppid), 0644)
+		assert.Nil(t, err)
+		defer os.Remove(file)
+
+		err = gs.WritePidFile(file)
+		assert.Error(t, err, "process "+ppid+" is already running")
+
+		// 不删除其他进程的文件
+		err = gs.RemovePidFile(file)
+		assert.Nil(t, err)
+		b, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), ppid)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=^$")
+		err := cmd.Run()
+		assert.Nil(t, err)
+		err = ioutil.WriteFile(file, []byte(strconv.Itoa(cmd.Process.Pid)), 0644)
+		assert.Nil(t, err)
+
+		err = gs.WritePidFile(file)
+		assert.Nil(t, err)
+		b, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, strings.TrimSpace(string(b)), pid)
+		assert.Nil(t, gs.RemovePidFile(file))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := ioutil.WriteFile(file, nil, 0644)
+		assert.Nil(t, err)
+		defer os.Remove(file)
+		err = gs.WritePidFile(file)
+		assert.Error(t, err, "invalid pid file")
+	})
+
+	t.Run("app", func(t *testing.T) {
+		os.Clearenv()
+		app := gs.NewApp()
+		app.Property(environ.SpringPidFile, file)
+		_, stop := runApp(t, app)
+
+		b, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, strings.TrimSpace(string(b)), pid)
+
+		stop()
+		_, err = os.Stat(file)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestConfigImport(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string, data string) {
+		file := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(file), 0755)
+		assert.Nil(t, err)
+		err = ioutil.WriteFile(file, []byte(data), 0644)
+		assert.Nil(t, err)
+	}
+
+	writeFile("config/application.yaml", `
+db:
+  url: app
+  user: app
+mq:
+  addr: app
+spring:
+  config:
+    import:
+      - ../shared/db.properties
+      - optional:../shared/missing.yaml
+      - ../secrets/
+`)
+	writeFile("shared/db.properties", "db.url=shared\nspring.config.import=mq.toml\n")
+	writeFile("shared/mq.toml", "[mq]\naddr = \"shared\"\n")
+	writeFile("secrets/db.password", "123456\n")
+	writeFile("secrets/mq/token", "abc")
+	writeFile("secrets/..data/ignored", "x")
+
+	t.Run("success", func(t *testing.T) {
+		os.Clearenv()
+		gs.Setenv("GS_SPRING_CONFIG_LOCATIONS", filepath.Join(dir, "config"))
+		p, stop := startApplication(t, "")
+		defer stop()
+		assert.Equal(t, p.Prop("db.url"), "shared")
+		assert.Equal(t, p.Prop("db.user"), "app")
+		assert.Equal(t, p.Prop("db.password"), "123456")
+		assert.Equal(t, p.Prop("mq.addr"), "shared")
+		assert.Equal(t, p.Prop("mq.token"), "abc")
+		assert.Nil(t, p.Prop("..data.ignored"))
+		origins := p.Origins("db.url")
+		assert.Equal(t, len(origins), 2)
+		assert.Equal(t, origins[1].String(), filepath.Join(dir, "shared/db.properties")+":1")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		writeFile("shared/mq.toml", "[spring.config]\nimport = \"db.properties\"\n")
+		os.Clearenv()
+		gs.Setenv("GS_SPRING_CONFIG_LOCATIONS", filepath.Join(dir, "config"))
+		err := gs.NewApp().Run()
+		assert.Error(t, err, "spring.config.import cycle .*db.properties -> .*mq.toml -> .*db.properties")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		writeFile("shared/mq.toml", "[spring.config]\nimport = \"mq-missing.toml\"\n")
+		os.Clearenv()
+		gs.Setenv("GS_SPRING_CONFIG_LOCATIONS", filepath.Join(dir, "config"))
+		err := gs.NewApp().Run()
+		assert.Error(t, err, "mq.toml import .*mq-missing.toml error")
+	})
+}
+
+func TestEncryptedProperty(t *testing.T) {
+
+	key, err := aesgcm.GenerateKey()
+	assert.Nil(t, err)
+	c, err := aesgcm.NewBase64(key)
+	assert.Nil(t, err)
+	s, err := c.Encrypt("123456")
+	assert.Nil(t, err)
+
+	os.Clearenv()
+	gs.Setenv(environ.SpringConfigEncryptKey, key)
+	gs.Setenv("GS_DB_PASSWORD", s)
+	defer conf.SetDecryptor(nil)
+
+	p, stop := startApplication(t, "")
+	defer stop()
+	assert.Equal(t, p.Prop("db.password"), "123456")
+	assert.Nil(t, p.Prop(environ.SpringConfigEncryptKey))
+	assert.Equal(t, p.Origins("db.password")[0].Value, s)
+}
+
+type StrictServerConfig struct {
+	Port int `value:"${port:=8080}"`
+}
+
+type StrictConfig struct {
+	Server StrictServerConfig `value:"${strict.server}"`
+}
+
+func TestConfigStrict(t *testing.T) {
+
+	os.Clearenv()
+	_ = os.Setenv("GS_STRICT_SERVER_PROT", "9090")
+	defer os.Clearenv()
+
+	app := gs.NewApp()
+	app.Property(environ.SpringConfigStrict, true)
+	app.Object(new(StrictConfig))
+	err := app.Run()
+	assert.Error(t, err, "unknown properties strict.server.prot")
+}
+
 func sortedKeys(m map[string]string) (keys []string) {
 	for k := range m {
 		keys = append(keys, k)
@@ -90,3 +311,383 @@ func sortedKeys(m map[string]string) (keys []string) {
 	sort.Strings(keys)
 	return
 }
+
+func TestRelaxedEnv(t *testing.T) {
+
+	os.Clearenv()
+	gs.Setenv("GS_WEB_SERVER_BASE__PATH", "/api")
+	gs.Setenv("GS_WEB_SERVER_HOSTS_0_NAME", "a")
+	gs.Setenv("GS_WEB_SERVER_HOSTS_1_NAME", "b")
+
+	p, stop := startApplication(t, "")
+	defer stop()
+	assert.Equal(t, p.Prop("web.server.base-path"), "/api")
+	assert.Equal(t, p.Prop("web.server.hosts[0].name"), "a")
+	assert.Equal(t, p.Prop("web.server.hosts[1].name"), "b")
+
+	var s struct {
+		BasePath string `value:"${basePath}"`
+		Hosts    []struct {
+			Name string `value:"${name}"`
+		} `value:"${hosts}"`
+	}
+	err := p.Bind(&s, conf.Key("web.server"))
+	assert.Nil(t, err)
+	assert.Equal(t, s.BasePath, "/api")
+	assert.Equal(t, len(s.Hosts), 2)
+	assert.Equal(t, s.Hosts[1].Name, "b")
+}
+
+type cmdConfig struct {
+	Name   string `value:"${name:=}"`
+	Offset int    `value:"${offset:=0}"`
+	Debug  bool   `value:"${debug:=false}"`
+}
+
+func TestLoadCmdArgs(t *testing.T) {
+
+	p := conf.New()
+	err := p.Catalog().Scan(reflect.TypeOf(cmdConfig{}), "${"+conf.RootKey+"}", "")
+	assert.Nil(t, err)
+
+	b := conf.NewBatch()
+	keys, rest := gs.LoadCmdArgs(b, []string{"--server.port=8080", "--debug", "input.txt",
+		"-offset", "-5", "--name", "go-spring", "--logging.file.path", "/var/log/a.log",
+		"-my.key", "v", "--verbose", "-mode=fast", "--spring.banner.visible", "extra",
+		"--help", "--", "-x", "--y=1"}, gs.BoolFlag(p.Catalog()))
+	assert.Nil(t, p.Get("server.port"))
+	p.Apply(b)
+
+	assert.Equal(t, keys, []string{"server.port", "debug", "offset", "name", "logging.file.path",
+		"my.key", "verbose", "mode", "spring.banner.visible", "help"})
+	assert.Equal(t, rest, []string{"input.txt", "extra", "-x", "--y=1"})
+	assert.Equal(t, p.Get("server.port"), "8080")
+	assert.Equal(t, p.Get("debug"), "true")
+	assert.Equal(t, p.Get("offset"), "-5")
+	assert.Equal(t, p.Get("name"), "go-spring")
+	assert.Equal(t, p.Get("logging.file.path"), "/var/log/a.log")
+	assert.Equal(t, p.Get("my.key"), "v")
+	assert.Equal(t, p.Get("verbose"), "true")
+	assert.Equal(t, p.Get("mode"), "fast")
+	assert.Equal(t, p.Get("spring.banner.visible"), "true")
+	assert.Equal(t, p.Get("help"), "true")
+	assert.Nil(t, p.Get("-server.port"))
+	assert.Nil(t, p.Get("x"))
+	assert.Equal(t, p.Origins("my.key")[0].Source, "cmd:-my.key")
+
+	// 最后一个属性后面没有参数
+	p = conf.New()
+	keys, rest = gs.LoadCmdArgs(b, []string{"a", "--name"}, gs.BoolFlag(p.Catalog()))
+	p.Apply(b)
+	assert.Equal(t, keys, []string{"name"})
+	assert.Equal(t, rest, []string{"a"})
+	assert.Equal(t, p.Get("name"), "true")
+}
+
+func TestCmdArgs(t *testing.T) {
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"app", "--debug", "input.txt", "--name", "go-spring", "--", "-x"}
+
+	os.Clearenv()
+	app := gs.NewApp()
+	c := new(cmdConfig)
+	app.Object(c)
+
+	ctx, stop := runApp(t, app)
+	defer stop()
+
+	assert.Equal(t, c.Name, "go-spring")
+	assert.True(t, c.Debug)
+	assert.Equal(t, ctx.Prop("debug"), "true")
+	assert.Equal(t, ctx.Args(), []string{"input.txt", "-x"})
+}
+
+func TestConfigWatch(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	modTime := time.Now()
+	writeFile := func(name string, data string) {
+		file := filepath.Join(dir, name)
+		err := ioutil.WriteFile(file, []byte(data), 0644)
+		assert.Nil(t, err)
+		// 保证修改时间一定发生变化
+		modTime = modTime.Add(time.Second)
+		err = os.Chtimes(file, modTime, modTime)
+		assert.Nil(t, err)
+	}
+
+	writeFile("application.properties", "spring.config.import=db.properties\nserver.port=8080\n")
+	writeFile("db.properties", "db.url=a\n")
+
+	os.Clearenv()
+	gs.Setenv("GS_SPRING_CONFIG_LOCATIONS", dir)
+	gs.Setenv("GS_SPRING_CONFIG_WATCH", true)
+	gs.Setenv("GS_SPRING_CONFIG_WATCH__INTERVAL", "20ms")
+
+	ports := make(chan int, 10)
+	changes := make(chan []string, 10)
+
+	app := gs.NewApp()
+	app.OnProperty("server.port", func(port int) { ports <- port })
+	app.OnPropertyChange(func(keys []string) { changes <- keys })
+	go app.Run()
+	defer app.ShutDown(errors.New("run test end"))
+
+	waitPort := func() int {
+		select {
+		case port := <-ports:
+			return port
+		case <-time.After(2 * time.Second):
+			t.Fatal("wait port timeout")
+			return 0
+		}
+	}
+
+	waitChange := func() []string {
+		select {
+		case keys := <-changes:
+			return keys
+		case <-time.After(2 * time.Second):
+			t.Fatal("wait change timeout")
+			return nil
+		}
+	}
+
+	assert.Equal(t, waitPort(), 8080)
+
+	writeFile("application.properties", "spring.config.import=db.properties\nserver.port=9090\n")
+	assert.Equal(t, waitPort(), 9090)
+	assert.Equal(t, waitChange(), []string{"server.port"})
+
+	writeFile("db.properties", "db.url=b\n")
+	assert.Equal(t, waitChange(), []string{"db.url"})
+	select {
+	case port := <-ports:
+		t.Fatalf("unexpected port %d", port)
+	default:
+	}
+}
+
+type fakeConfigSource struct {
+	m  map[string]string
+	ch chan map[string]string
+}
+
+func (s *fakeConfigSource) Name() string {
+	return "fake"
+}
+
+func (s *fakeConfigSource) Load(ctx context.Context) (map[string]string, error) {
+	return s.m, nil
+}
+
+func (s *fakeConfigSource) Watch(ctx context.Context) (<-chan map[string]string, error) {
+	return s.ch, nil
+}
+
+func TestConfigSource(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "application.properties")
+	err = ioutil.WriteFile(file, []byte("a=file\nb=file\nc=file\n"), 0644)
+	assert.Nil(t, err)
+
+	os.Clearenv()
+	gs.Setenv("GS_SPRING_CONFIG_LOCATIONS", dir)
+	gs.Setenv("GS_C", "env")
+
+	s := &fakeConfigSource{
+		m:  map[string]string{"b": "source", "c": "source"},
+		ch: make(chan map[string]string),
+	}
+
+	changes := make(chan []string, 10)
+	app := gs.NewApp()
+	app.AddConfigSource(s)
+	app.OnPropertyChange(func(keys []string) { changes <- keys })
+
+	p, stop := runApp(t, app)
+	defer stop()
+
+	assert.Equal(t, p.Prop("a"), "file")
+	assert.Equal(t, p.Prop("b"), "source")
+	assert.Equal(t, p.Prop("c"), "env")
+	assert.Equal(t, p.Origins("b")[1].Source, "fake")
+
+	// 启动之后直接设置的属性不会因为配置源的更新而丢失
+	app.Property("e", "live")
+
+	s.ch <- map[string]string{"c": "source", "d": "source"}
+	select {
+	case keys := <-changes:
+		assert.Equal(t, keys, []string{"b", "d"})
+	case <-time.After(2 * time.Second):
+		t.Fatal("wait change timeout")
+	}
+	assert.Equal(t, p.Prop("b"), "file")
+	assert.Equal(t, p.Prop("c"), "env")
+	assert.Equal(t, p.Prop("d"), "source")
+	assert.Equal(t, p.Prop("e"), "live")
+}
+
+func TestConfigProfiles(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string, data string) {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644)
+		assert.Nil(t, err)
+	}
+
+	writeFile("application.yaml", `
+spring:
+  profiles:
+    group:
+      prod: prod-db,prod-mq
+db:
+  url: default
+mq:
+  addr: default
+---
+spring.config.activate.on-profile: prod-mq
+mq:
+  addr: prod-mq-doc
+---
+spring.config.activate.on-profile: dev
+db:
+  url: dev-doc
+`)
+	writeFile("application-prod-db.properties", "db.url=prod-db\ndb.user=prod\n")
+	writeFile("application-local.properties", "db.user=local\n")
+
+	os.Clearenv()
+	gs.Setenv("GS_SPRING_CONFIG_LOCATIONS", dir)
+	gs.Setenv("GS_SPRING_PROFILES_ACTIVE", "prod,local")
+
+	p, stop := startApplication(t, "")
+	defer stop()
+	assert.Equal(t, p.Prop("db.url"), "prod-db")
+	assert.Equal(t, p.Prop("db.user"), "local")
+	assert.Equal(t, p.Prop("mq.addr"), "prod-mq-doc")
+}
+
+func TestLoggingFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Clearenv()
+	file := filepath.Join(dir, "logs", "app.log")
+	gs.Setenv("GS_LOGGING_FILE_PATH", file)
+	gs.Setenv("GS_LOGGING_FILE_FORMAT", "json")
+	gs.Setenv("GS_LOGGING_FILE_MAX-SIZE", "10MB")
+
+	app := gs.NewApp()
+
+	var f *log.RollingFile
+	type LogFileAware struct{}
+	app.Provide(func(b *log.RollingFile) LogFileAware {
+		f = b
+		return LogFileAware{}
+	})
+
+	_, stop := runApp(t, app)
+	log.With("user", 42).Info("hello")
+	stop()
+
+	// 容器关闭时销毁日志文件
+	_, err = f.Write([]byte("closed\n"))
+	assert.Equal(t, err, os.ErrClosed)
+
+	b, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	var msgs []string
+	for _, line := range lines {
+		var m map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &m))
+		msgs = append(msgs, m["msg"].(string))
+	}
+	assert.True(t, containsString(msgs, "hello"))
+	assert.True(t, containsString(msgs, "goroutines exited"))
+}
+
+func TestOpenLogFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	open := func(m map[string]interface{}) error {
+		return gs.NewApp().OpenLogFile(conf.Map(m))
+	}
+
+	// 没有设置路径时仍然输出到控制台
+	err = open(map[string]interface{}{"logging.file.format": "xml"})
+	assert.Nil(t, err)
+
+	file := filepath.Join(dir, "app.log")
+	err = open(map[string]interface{}{
+		"logging.file.path":   file,
+		"logging.file.format": "xml",
+	})
+	assert.Error(t, err, "logging.file.format: invalid format \"xml\"")
+
+	err = open(map[string]interface{}{
+		"logging.file.path":     file,
+		"logging.file.rotation": "weekly",
+	})
+	assert.Error(t, err, "logging.file.rotation: .*weekly")
+
+	err = open(map[string]interface{}{
+		"logging.file.path":     file,
+		"logging.file.max-size": "ten",
+	})
+	assert.Error(t, err, `invalid byte size "ten"`)
+}
+
+func TestLoggingFileCatalog(t *testing.T) {
+
+	catalog := gs.NewApp().Catalog()
+	m, ok := catalog.Lookup("logging.file.max-size")
+	assert.True(t, ok)
+	assert.Equal(t, m.Default, "10MB")
+	m, ok = catalog.Lookup("logging.file.compress")
+	assert.True(t, ok)
+	assert.Equal(t, m.Type, "bool")
+	assert.True(t, catalog.Unknown("logging.file.pth"))
+
+	p := conf.New()
+	b := conf.NewBatch()
+	_, rest := gs.LoadCmdArgs(b, []string{"--logging.file.compress", "a.txt"}, gs.BoolFlag(catalog))
+	p.Apply(b)
+	assert.Equal(t, p.Get("logging.file.compress"), "true")
+	assert.Equal(t, rest, []string{"a.txt"})
+
+	os.Clearenv()
+	gs.Setenv("GS_LOGGING_FILE_PTH", "app.log")
+	app := gs.NewApp()
+	app.Property(environ.SpringConfigStrict, true)
+	err := app.Run()
+	assert.Error(t, err, "unknown properties logging.file.pth")
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"strings"
 
 	"github.com/go-spring/spring-core/gs/bean"
 	"github.com/go-spring/spring-core/gs/cond"
@@ -45,7 +46,8 @@ type Context interface {
 // Arg 用于为函数参数提供绑定值。可以是 bean.Selector 类型，表示注入 bean ；
 // 可以是 ${X:=Y} 形式的字符串，表示属性绑定或者注入 bean ；可以是 ValueArg
 // 类型，表示不从 IoC 容器获取而是用户传入的普通值；可以是 IndexArg 类型，表示
-// 带有下标的参数绑定；可以是 *optionArg 类型，用于为 Option 方法提供参数绑定。
+// 带有下标的参数绑定；可以是 *optionArg 类型，用于为 Option 方法提供参数绑定；
+// 可以是 PrefixArg 类型，用于为属性绑定设置属性名前缀。
 type Arg interface{}
 
 // IndexArg 包含下标的参数绑定。
@@ -90,6 +92,33 @@ func Value(v interface{}) ValueArg {
 	return ValueArg{v: v}
 }
 
+// PrefixArg 为函数的属性绑定设置属性名前缀，它不对应任何一个函数参数。
+type PrefixArg struct {
+	prefix string
+}
+
+// Prefix 返回设置属性名前缀的参数绑定。没有指定绑定值的值类型参数绑定 prefix
+// 对应的属性，${key} 形式的参数绑定 prefix.key 对应的属性。因此结构体的 value
+// 标签可以使用相对于 prefix 的属性名，比如 ${host} ，同一个结构体就可以在不同的
+// 前缀下绑定多次，比如 gs.Provide(NewClient, arg.Prefix("redis.session")) 。
+func Prefix(prefix string) PrefixArg {
+	return PrefixArg{prefix: prefix}
+}
+
+// withPrefix 返回加上属性名前缀之后的 tag ，省略属性名的 ${:=def} 保持不变。
+func withPrefix(prefix string, tag string) string {
+	if prefix == "" {
+		return tag
+	}
+	if tag == "" || tag == "${}" {
+		return "${" + prefix + "}"
+	}
+	if strings.HasPrefix(tag, "${") && !strings.HasPrefix(tag, "${:=") {
+		return "${" + prefix + "." + tag[2:]
+	}
+	return tag
+}
+
 // argList 函数参数绑定列表。
 type argList struct {
 
@@ -98,10 +127,27 @@ type argList struct {
 
 	// fnType 函数的类型。
 	fnType reflect.Type
+
+	// prefix 属性绑定的属性名前缀。
+	prefix string
 }
 
 func newArgList(fnType reflect.Type, args []Arg) (*argList, error) {
 
+	// 属性名前缀不对应任何一个函数参数。
+	var (
+		prefix string
+		rest   []Arg
+	)
+	for _, arg := range args {
+		if p, ok := arg.(PrefixArg); ok {
+			prefix = p.prefix
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	args = rest
+
 	// 计算函数类型中包含不可变参数的数量。
 	fixedArgCount := fnType.NumIn()
 	if fnType.IsVariadic() {
@@ -175,7 +221,7 @@ func newArgList(fnType reflect.Type, args []Arg) (*argList, error) {
 		}
 	}
 
-	return &argList{fnType: fnType, args: fnArgs}, nil
+	return &argList{fnType: fnType, args: fnArgs, prefix: prefix}, nil
 }
 
 // get 返回所有绑定参数的真实值，fileLine 是函数定义所在的文件信息。
@@ -252,6 +298,9 @@ func (r *argList) getArg(ctx Context, arg Arg, t reflect.Type, fileLine string)
 	if tag == "" {
 		tag = "${}"
 	}
+	if _, ok := arg.(string); ok {
+		tag = withPrefix(r.prefix, tag)
+	}
 	if err = ctx.Bind(v, tag); err != nil {
 		return reflect.Value{}, err
 	}
@@ -341,6 +390,43 @@ func Bind(fn interface{}, args []Arg, skip int) (*Callable, error) {
 	return r, nil
 }
 
+// FileLine 返回函数绑定的代码位置。
+func (r *Callable) FileLine() string {
+	return r.fileLine
+}
+
+// ForEachBind 遍历所有通过属性绑定获取值的参数，包括 Option 函数的参数，fn 的
+// 参数是参数类型及其属性绑定使用的 tag 。
+func (r *Callable) ForEachBind(fn func(t reflect.Type, tag string)) {
+
+	fnType := r.argList.fnType
+	numIn := fnType.NumIn()
+	variadic := fnType.IsVariadic()
+
+	for idx, a := range r.argList.args {
+
+		var t reflect.Type
+		if variadic && idx >= numIn-1 {
+			t = fnType.In(numIn - 1).Elem()
+		} else {
+			t = fnType.In(idx)
+		}
+
+		switch g := a.(type) {
+		case *optionArg:
+			g.r.ForEachBind(fn)
+		case string:
+			if util.IsBeanReceiver(t) {
+				continue
+			}
+			if g == "" {
+				g = "${}"
+			}
+			fn(t, withPrefix(r.argList.prefix, g))
+		}
+	}
+}
+
 // Call 通过反射机制获取函数的绑定参数并执行函数，最后返回函数的执行结果。
 func (r *Callable) Call(ctx Context) ([]reflect.Value, error) {
 
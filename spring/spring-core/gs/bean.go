@@ -45,6 +45,24 @@ const (
 	Deleted   = beanStatus(5) // 已删除
 )
 
+func (status beanStatus) String() string {
+	switch status {
+	case Default:
+		return "default"
+	case Resolving:
+		return "resolving"
+	case Resolved:
+		return "resolved"
+	case Wiring:
+		return "wiring"
+	case Wired:
+		return "wired"
+	case Deleted:
+		return "deleted"
+	}
+	return ""
+}
+
 // BeanDefinition bean 元数据。
 type BeanDefinition struct {
 
@@ -20,6 +20,7 @@ import (
 	"context"
 	"os"
 	"reflect"
+	"runtime"
 
 	"github.com/go-spring/spring-core/gs/arg"
 	"github.com/go-spring/spring-core/web"
@@ -55,6 +56,23 @@ func OnProperty(key string, fn interface{}) {
 	app.OnProperty(key, fn)
 }
 
+// OnReload 注册收到 SIGHUP 信号后执行的回调，回调按照注册的顺序执行。
+func OnReload(fn func(ctx AppContext) error) {
+	app.OnReload(fn)
+}
+
+// AddConfigSource 添加配置源，配置源的属性优先级高于配置文件，低于环境变量和命令
+// 行参数。
+func AddConfigSource(s ConfigSource) {
+	app.AddConfigSource(s)
+}
+
+// OnPropertyChange 注册配置文件或者配置源重新加载后执行的回调，keys 是新增、删除以及值
+// 发生变化的属性名。
+func OnPropertyChange(fn func(keys []string)) {
+	app.OnPropertyChange(fn)
+}
+
 // Property 设置 key 对应的属性值，如果 key 对应的属性值已经存在则 Set 方法会
 // 覆盖旧值。Set 方法除了支持 string 类型的属性值，还支持 int、uint、bool 等
 // 其他基础数据类型的属性值。特殊情况下，Set 方法也支持 slice 、map 与基础数据
@@ -64,6 +82,12 @@ func Property(key string, value interface{}) {
 	app.Property(key, value)
 }
 
+// Convert 注册只对当前应用有效的类型转换器，转换器的函数原型为
+// func(string)(type,error) 。
+func Convert(fn interface{}) {
+	app.Convert(fn)
+}
+
 // Object 注册对象形式的 bean ，需要注意的是该方法在注入开始后就不能再调用了。
 func Object(i interface{}) *BeanDefinition {
 	return app.c.register(NewBean(reflect.ValueOf(i)))
@@ -74,6 +98,14 @@ func Provide(ctor interface{}, args ...arg.Arg) *BeanDefinition {
 	return app.c.register(NewBean(ctor, args...))
 }
 
+// ProvideForEach 为 key 对应的 map 属性的每个条目注册一个构造函数形式的 bean ，
+// bean 的名称是条目的 key ，构造函数的属性绑定以 key.name 为前缀。需要注意的是
+// 该方法在注入开始后就不能再调用了。
+func ProvideForEach(key string, ctor interface{}, args ...arg.Arg) {
+	_, file, line, _ := runtime.Caller(1)
+	app.c.provideForEach(forEachBean{key: key, ctor: ctor, args: args, file: file, line: line})
+}
+
 // Go 创建安全可等待的 goroutine，fn 要求的 ctx 对象由 IoC 容器提供，当 IoC 容
 // 器关闭时 ctx会 发出 Done 信号， fn 在接收到此信号后应当立即退出。
 func Go(fn func(ctx context.Context)) {
@@ -19,9 +19,11 @@ package cond
 
 import (
 	"errors"
+	"fmt"
 	"go/token"
 	"go/types"
 	"strings"
+	"time"
 
 	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/gs/bean"
@@ -44,6 +46,29 @@ type Context interface {
 	Find(selector bean.Selector) ([]bean.Definition, error)
 }
 
+// PropContext 在 Context 的基础上提供类型化的属性访问方法，IoC 容器传给条件的
+// Context 都实现了该接口，可以通过类型断言获取。这些方法没有加在 Context 上是为
+// 了不影响已有的 Context 实现。
+type PropContext interface {
+	Context
+
+	// GetString 等方法返回 key 对应的指定类型的属性值，属性值不存在时返回
+	// conf.Def 设置的默认值，没有默认值或者类型转换失败时返回错误。
+	GetString(key string, opts ...conf.GetOption) (string, error)
+	GetInt(key string, opts ...conf.GetOption) (int, error)
+	GetBool(key string, opts ...conf.GetOption) (bool, error)
+	GetDuration(key string, opts ...conf.GetOption) (time.Duration, error)
+	GetStringSlice(key string, opts ...conf.GetOption) ([]string, error)
+	GetStringMap(key string, opts ...conf.GetOption) (map[string]string, error)
+
+	// Has 返回 key 对应的属性值是否存在，HasPrefix 返回是否存在 prefix 的子属性。
+	Has(key string) bool
+	HasPrefix(prefix string) bool
+
+	// Sub 返回以 prefix 为根的属性列表。
+	Sub(prefix string) *conf.Properties
+}
+
 // Condition 条件接口，条件成立 Matches 方法返回 true，否则返回 false。
 type Condition interface {
 	Matches(ctx Context) (bool, error)
@@ -99,6 +124,64 @@ func (c *onProperty) Matches(ctx Context) (bool, error) {
 	return cast.ToBoolE(ret.Value.String())
 }
 
+// onProfile 基于激活的 profile 匹配的 Condition 实现。
+type onProfile struct{ profile string }
+
+func (c *onProfile) Matches(ctx Context) (bool, error) {
+	profiles := Profiles(func(key string) interface{} { return ctx.Prop(key) })
+	for _, s := range profiles {
+		if s == c.profile {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Profiles 返回激活的 profile 列表。spring.profiles.active 可以是逗号分隔的列
+// 表，排在后面的优先级更高；spring.profiles.group.<name> 定义 profile 组，组名
+// 激活时组的成员紧跟在组名之后激活。prop 用于获取属性值，属性值也可以是列表。
+func Profiles(prop func(key string) interface{}) []string {
+	var (
+		ret []string
+		add func(key string)
+	)
+	seen := make(map[string]struct{})
+	add = func(key string) {
+		for _, s := range propList(prop, key) {
+			if _, ok := seen[s]; ok {
+				continue
+			}
+			seen[s] = struct{}{}
+			ret = append(ret, s)
+			add(environ.SpringProfilesGroup + "." + s)
+		}
+	}
+	add(environ.SpringProfilesActive)
+	return ret
+}
+
+// propList 返回逗号分隔的或者列表形式的属性值。
+func propList(prop func(key string) interface{}, key string) []string {
+	var ret []string
+	if v := prop(key); v != nil {
+		for _, s := range strings.Split(cast.ToString(v), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	for i := 0; ; i++ {
+		v := prop(fmt.Sprintf("%s[%d]", key, i))
+		if v == nil {
+			return ret
+		}
+		if s := strings.TrimSpace(cast.ToString(v)); s != "" {
+			ret = append(ret, s)
+		}
+	}
+}
+
 // onBean 基于符合条件的 bean 必须存在的 Condition 实现。
 type onBean struct{ selector bean.Selector }
 
@@ -359,12 +442,13 @@ func (c *conditional) OnMatches(fn Matches) *conditional {
 	return c.On(&onMatches{fn: fn})
 }
 
-// OnProfile 返回一个以 spring.profile 属性值是否匹配为开始条件的计算式。
+// OnProfile 返回一个以 profile 是否激活为开始条件的计算式。
 func OnProfile(profile string) *conditional {
 	return New().OnProfile(profile)
 }
 
-// OnProfile 添加一个 spring.profile 属性值是否匹配的条件。
+// OnProfile 添加一个 profile 是否激活的条件，profile 是任何一个激活的 profile
+// 或者激活的 profile 组的成员时条件成立。
 func (c *conditional) OnProfile(profile string) *conditional {
-	return c.OnProperty(environ.SpringProfilesActive, HavingValue(profile))
+	return c.On(&onProfile{profile: profile})
 }
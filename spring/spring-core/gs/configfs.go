@@ -0,0 +1,45 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"io/fs"
+	"path"
+)
+
+// ConfigFS 注册嵌入式配置目录，比如通过 embed.FS 打包到程序中的目录，可以在
+// starter 中提供默认的配置文件，而不必将默认值写在 value 标签中。目录中的
+// application.yaml 以及 application-{profile}.yaml 等配置文件的优先级低于所有
+// 其他来源的配置文件，先注册的目录优先级更低。io/fs 需要 Go 1.16 ，因此该方法只
+// 在 Go 1.16 及以上版本可用，更早的版本中 App 的其他功能不受影响。
+func (app *App) ConfigFS(fsys fs.FS, dir string) {
+	app.configFS = append(app.configFS, configFS{
+		dir: path.Clean(dir),
+		read: func(file string) ([]byte, error) {
+			return fs.ReadFile(fsys, file)
+		},
+	})
+}
+
+// ConfigFS 注册嵌入式配置目录，比如 gs.ConfigFS(embeddedFS, "config/") ，
+// 其中的配置文件的优先级低于所有其他来源的配置文件，需要 Go 1.16 及以上版本。
+func ConfigFS(fsys fs.FS, dir string) {
+	app.ConfigFS(fsys, dir)
+}
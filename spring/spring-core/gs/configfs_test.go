@@ -0,0 +1,66 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-stl/assert"
+)
+
+func TestConfigFS(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "application.properties"), []byte("db.url=file\n"), 0644)
+	assert.Nil(t, err)
+
+	base := fstest.MapFS{
+		"config/application.yaml": {Data: []byte("db:\n  url: embed\n  user: embed\n  pool: 4\n")},
+	}
+	starter := fstest.MapFS{
+		"config/application.yaml":      {Data: []byte("db:\n  user: starter\n")},
+		"config/application-dev.yaml":  {Data: []byte("db:\n  pool: 8\n")},
+		"config/application-prod.yaml": {Data: []byte("db:\n  pool: 16\n")},
+	}
+
+	os.Clearenv()
+	gs.Setenv("GS_SPRING_CONFIG_LOCATIONS", dir)
+	gs.Setenv("GS_SPRING_PROFILES_ACTIVE", "dev")
+
+	app := gs.NewApp()
+	app.ConfigFS(base, "config/")
+	app.ConfigFS(starter, "config")
+
+	p, stop := runApp(t, app)
+	defer stop()
+
+	assert.Equal(t, p.Prop("db.url"), "file")
+	assert.Equal(t, p.Prop("db.user"), "starter")
+	assert.Equal(t, p.Prop("db.pool"), "8")
+	assert.Equal(t, p.Origins("db.user")[0].Source, "embed:config/application.yaml")
+}
@@ -29,6 +29,10 @@ const IncludeEnvPatterns = "INCLUDE_ENV_PATTERNS"
 // ExcludeEnvPatterns 排除符合条件的环境变量。
 const ExcludeEnvPatterns = "EXCLUDE_ENV_PATTERNS"
 
+// SpringConfigEncryptKey 保存 base64 编码的 AES-GCM 密钥的环境变量，用于解密
+// ENC(...) 形式的属性值，该环境变量不会被加载到属性列表中。
+const SpringConfigEncryptKey = "SPRING_CONFIG_ENCRYPT_KEY"
+
 // EnablePandora 是否允许 gs.Pandora 接口。
 const EnablePandora = "enable-pandora"
 
@@ -41,11 +45,41 @@ const SpringConfigLocations = "spring.config.locations"
 // SpringConfigExtensions 配置文件的扩展名，支持逗号分隔。
 const SpringConfigExtensions = "spring.config.extensions"
 
+// SpringConfigImport 配置文件中导入其他配置文件或者目录，支持逗号分隔或者列表。
+const SpringConfigImport = "spring.config.import"
+
+// SpringConfigEncryptKeyFile 保存 base64 编码的 AES-GCM 密钥的文件，优先级低于
+// SPRING_CONFIG_ENCRYPT_KEY 环境变量。
+const SpringConfigEncryptKeyFile = "spring.config.encrypt.key-file"
+
+// SpringConfigStrict 是否禁止出现未知属性，即位于结构体绑定的属性前缀之下但是没
+// 有对应字段的属性。
+const SpringConfigStrict = "spring.config.strict"
+
+// SpringConfigWatch 是否监视配置文件的变化，配置文件发生变化后重新加载属性。
+const SpringConfigWatch = "spring.config.watch"
+
+// SpringConfigWatchInterval 轮询配置文件是否发生变化的时间间隔，默认是 5s 。
+const SpringConfigWatchInterval = "spring.config.watch-interval"
+
 // SpringBannerVisible 是否显示 banner。
 const SpringBannerVisible = "spring.banner.visible"
 
-// SpringProfilesActive 当前应用的 profile 配置。
+// SpringProfilesActive 当前应用激活的 profile ，支持逗号分隔，排在后面的优先级更高。
 const SpringProfilesActive = "spring.profiles.active"
 
+// SpringProfilesGroup profile 组的前缀，比如 spring.profiles.group.prod=prod-db,prod-mq
+// 表示激活 prod 时同时激活 prod-db 和 prod-mq 。
+const SpringProfilesGroup = "spring.profiles.group"
+
+// SpringConfigActivateOnProfile YAML 文件中使用 --- 分隔的文档只在指定的 profile
+// 激活时生效，支持逗号分隔，匹配其中任何一个即可。
+const SpringConfigActivateOnProfile = "spring.config.activate.on-profile"
+
 // SpringApplicationName 当前应用的名称。
 const SpringApplicationName = "spring.application.name"
+
+// LoggingFile 日志文件配置的前缀，设置 logging.file.path 后日志改为写入按照大小
+// 和时间滚动的文件，其他配置项有 format 、max-size 、rotation 、max-files 、
+// max-age 以及 compress 。
+const LoggingFile = "logging.file"
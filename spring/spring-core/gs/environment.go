@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/conf/dotenv"
 	"github.com/go-spring/spring-core/gs/environ"
 )
 
@@ -34,39 +35,88 @@ type Environment interface {
 }
 
 type environment struct {
-	p *conf.Properties
+	p    *conf.Properties
+	c    *conf.Catalog // 用于判断命令行中的属性是否为布尔类型
+	keys []string      // 通过 GS_ 环境变量和命令行参数设置的属性
+	args []string      // 命令行中的位置参数
 }
 
-func newEnvironment() *environment {
-	return &environment{p: conf.New()}
+func newEnvironment(c *conf.Catalog) *environment {
+	return &environment{p: conf.New(), c: c}
 }
 
-// loadCmdArgs 加载 -name value 形式的命令行参数。
-func loadCmdArgs(p *conf.Properties) {
-	for i := 0; i < len(os.Args); i++ {
+// loadCmdArgs 加载命令行参数，支持 --key=value 、--key value 、-key=value 以及
+// -key value 等形式，-- 之后的参数都不再被当作属性。属性后面紧跟的不是属性的参数
+// 被当作属性值，除非 isBool 表明这是一个布尔类型的属性，布尔类型的属性以及后面没
+// 有值的属性被当作标志，其值为 true 。属性保存到 b 中，返回设置的属性名以及剩余
+// 的位置参数。
+func loadCmdArgs(b *conf.Batch, args []string, isBool func(key string) bool) (keys []string, rest []string) {
+	for i := 0; i < len(args); i++ {
+
+		s := args[i]
+		if s == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
+		}
 
-		s := os.Args[i]
-		if !strings.HasPrefix(s, "-") {
+		if !isFlag(s) {
+			rest = append(rest, s)
 			continue
 		}
 
-		k, v := s[1:], ""
-		if i >= len(os.Args)-1 {
-			p.Set(k, v)
-			break
+		k := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "-")
+		v := "true"
+		if ss := strings.SplitN(k, "=", 2); len(ss) == 2 {
+			k, v = ss[0], ss[1]
+		} else if i < len(args)-1 && !isFlag(args[i+1]) && args[i+1] != "--" && !isBool(k) {
+			v = args[i+1]
+			i++
 		}
 
-		if !strings.HasPrefix(os.Args[i+1], "-") {
-			v = os.Args[i+1]
-			i++
+		keys = append(keys, k)
+		b.Set(k, v, conf.Source("cmd:"+s))
+	}
+	return
+}
+
+// boolKeys 框架使用的布尔类型的属性，它们在命令行中不会使用后面的参数作为属性值。
+var boolKeys = map[string]bool{
+	"help":                      true,
+	environ.EnablePandora:       true,
+	environ.SpringConfigStrict:  true,
+	environ.SpringConfigWatch:   true,
+	environ.SpringBannerVisible: true,
+}
+
+// boolFlag 返回判断 key 是否为布尔类型属性的函数，即框架使用的布尔类型的属性，
+// 或者在 catalog 中存在 bool 类型的元数据的属性。
+func boolFlag(catalog *conf.Catalog) func(key string) bool {
+	return func(key string) bool {
+		if boolKeys[key] {
+			return true
 		}
-		p.Set(k, v)
+		m, ok := catalog.Lookup(key)
+		return ok && m.Type == "bool"
 	}
 }
 
+// isFlag 返回参数是否为 -key 或者 --key 形式的属性，-1 这样的负数不是属性。
+func isFlag(s string) bool {
+	if !strings.HasPrefix(s, "-") {
+		return false
+	}
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "-")
+	if s == "" {
+		return false
+	}
+	c := s[0]
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
 // loadSystemEnv 添加符合 includes 条件的环境变量，排除符合 excludes 条件的
-// 环境变量。如果发现存在允许通过环境变量覆盖的属性名，那么保存时转换成真正的属性名。
-func loadSystemEnv(p *conf.Properties) error {
+// 环境变量。如果发现存在允许通过环境变量覆盖的属性名，那么保存时转换成真正的属性名，
+// 并且返回这些属性名。属性保存到 b 中。
+func loadSystemEnv(b *conf.Batch) ([]string, error) {
 
 	toRex := func(patterns []string) ([]*regexp.Regexp, error) {
 		var rex []*regexp.Regexp
@@ -86,7 +136,7 @@ func loadSystemEnv(p *conf.Properties) error {
 	}
 	includeRex, err := toRex(includes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var excludes []string
@@ -95,7 +145,7 @@ func loadSystemEnv(p *conf.Properties) error {
 	}
 	excludeRex, err := toRex(excludes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	matches := func(rex []*regexp.Regexp, s string) bool {
@@ -107,6 +157,7 @@ func loadSystemEnv(p *conf.Properties) error {
 		return false
 	}
 
+	var keys []string
 	for _, env := range os.Environ() {
 
 		kv := strings.SplitN(env, "=", 2)
@@ -115,31 +166,37 @@ func loadSystemEnv(p *conf.Properties) error {
 		}
 
 		k, v := kv[0], kv[1]
-		if k == "" || v == "" {
+		if k == "" || v == "" || k == environ.SpringConfigEncryptKey {
 			continue
 		}
 
 		if strings.HasPrefix(k, EnvPrefix) {
-			propKey := strings.TrimPrefix(k, EnvPrefix)
-			propKey = strings.ReplaceAll(propKey, "_", ".")
-			p.Set(strings.ToLower(propKey), v)
+			propKey := dotenv.PropKey(strings.TrimPrefix(k, EnvPrefix))
+			keys = append(keys, propKey)
+			b.Set(propKey, v, conf.Source("env:"+k))
 			continue
 		}
 
 		if matches(excludeRex, k) || !matches(includeRex, k) {
 			continue
 		}
-		p.Set(k, v)
+		b.Set(k, v, conf.Source("env:"+k))
 	}
-	return nil
+	return keys, nil
 }
 
+// prepare 加载环境变量和命令行参数，它们的数量可能很多，因此先保存到 Batch 中再
+// 一次性发布，命令行参数在环境变量之后设置，因此优先级更高。
 func (e *environment) prepare() error {
-	err := loadSystemEnv(e.p)
+	b := conf.NewBatch()
+	keys, err := loadSystemEnv(b)
 	if err != nil {
 		return err
 	}
-	loadCmdArgs(e.p)
+	cmdKeys, args := loadCmdArgs(b, os.Args[1:], boolFlag(e.c))
+	e.p.Apply(b)
+	e.keys = append(keys, cmdKeys...)
+	e.args = args
 	return nil
 }
 
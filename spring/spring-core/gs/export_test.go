@@ -0,0 +1,41 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"os"
+
+	"github.com/go-spring/spring-core/conf"
+)
+
+// 导出内部的函数，供 gs_test 包中的测试直接验证。
+var (
+	WritePidFile  = writePidFile
+	RemovePidFile = removePidFile
+	LoadCmdArgs   = loadCmdArgs
+	BoolFlag      = boolFlag
+)
+
+// HandleSignal 直接处理 sig 信号，不需要真正地向进程发送信号。
+func (app *App) HandleSignal(ctx AppContext, sig os.Signal) {
+	app.handleSignal(ctx, sig)
+}
+
+// OpenLogFile 根据 p 中 logging.file 前缀的属性打开日志文件。
+func (app *App) OpenLogFile(p *conf.Properties) error {
+	return app.openLogFile(p)
+}
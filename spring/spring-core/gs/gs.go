@@ -25,6 +25,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
 	"runtime/debug"
 	"sort"
 	"strings"
@@ -65,6 +66,7 @@ type Container struct {
 	cancel context.CancelFunc
 
 	beans       []*BeanDefinition
+	forEach     []forEachBean
 	beansById   map[string]*BeanDefinition
 	beansByName map[string][]*BeanDefinition
 	beansByType map[reflect.Type][]*BeanDefinition
@@ -100,6 +102,12 @@ func (c *Container) Property(key string, value interface{}) {
 	c.p.Set(key, value)
 }
 
+// Convert 注册只对当前容器有效的类型转换器，它的优先级高于 conf.Convert 注册的
+// 全局类型转换器，转换器的函数原型为 func(string)(type,error) 。
+func (c *Container) Convert(fn interface{}) {
+	c.p.Convert(fn)
+}
+
 func (c *Container) register(b *BeanDefinition) *BeanDefinition {
 	if c.state != Unrefreshed {
 		panic(errors.New("should call before Refresh"))
@@ -118,6 +126,59 @@ func (c *Container) Provide(ctor interface{}, args ...arg.Arg) *BeanDefinition {
 	return c.register(NewBean(ctor, args...))
 }
 
+// forEachBean 通过 ProvideForEach 注册的一组构造函数形式的 bean 。
+type forEachBean struct {
+	key  string
+	ctor interface{}
+	args []arg.Arg
+	file string
+	line int
+}
+
+// ProvideForEach 为 key 对应的 map 属性的每个条目注册一个构造函数形式的 bean ，
+// bean 的名称是条目的 key ，构造函数的属性绑定以 key.name 为前缀，参见
+// arg.Prefix 。比如 redis.instances.session.host 和 redis.instances.cache.host
+// 会注册名为 session 和 cache 的两个 bean 。需要注意的是该方法在注入开始后就不
+// 能再调用了。
+func (c *Container) ProvideForEach(key string, ctor interface{}, args ...arg.Arg) {
+	_, file, line, _ := runtime.Caller(1)
+	c.provideForEach(forEachBean{key: key, ctor: ctor, args: args, file: file, line: line})
+}
+
+func (c *Container) provideForEach(e forEachBean) {
+	if c.state != Unrefreshed {
+		panic(errors.New("should call before Refresh"))
+	}
+	NewBean(e.ctor, e.args...) // 提前检查构造函数和参数是否有效
+	c.forEach = append(c.forEach, e)
+}
+
+// registerForEach 根据属性注册通过 ProvideForEach 注册的 bean 。
+func (c *Container) registerForEach() {
+	for _, e := range c.forEach {
+		for _, name := range childNames(c.p, e.key) {
+			args := append(e.args[:len(e.args):len(e.args)], arg.Prefix(e.key+"."+name))
+			b := NewBean(e.ctor, args...).Name(name)
+			b.file, b.line = e.file, e.line
+			c.register(b)
+		}
+	}
+}
+
+// childNames 返回 key 对应的 map 属性的所有条目的 key ，按照字典序排列。
+func childNames(p *conf.Properties, key string) []string {
+	var names []string
+	for _, k := range p.Sub(key).Keys() {
+		if i := strings.IndexAny(k, ".["); i >= 0 {
+			k = k[:i]
+		}
+		if n := len(names); n == 0 || names[n-1] != k {
+			names = append(names, k)
+		}
+	}
+	return names
+}
+
 // Go 创建安全可等待的 goroutine，fn 要求的 ctx 对象由 IoC 容器提供，当 IoC 容
 // 器关闭时 ctx会 发出 Done 信号， fn 在接收到此信号后应当立即退出。
 func (c *Container) Go(fn func(ctx context.Context)) {
@@ -263,6 +324,8 @@ func (c *Container) Refresh() error {
 		return errors.New("container already refreshed")
 	}
 
+	c.registerForEach()
+
 	enablePandora := cast.ToBool(c.p.Get(environ.EnablePandora))
 	if enablePandora {
 		c.Object(&pandora{c}).Export((*Pandora)(nil))
@@ -270,6 +333,10 @@ func (c *Container) Refresh() error {
 
 	c.state = Refreshing
 
+	for _, b := range c.beans {
+		c.scanBean(b)
+	}
+
 	for _, b := range c.beans {
 		if err := c.registerBean(b); err != nil {
 			return err
@@ -318,6 +385,37 @@ func (c *Container) Refresh() error {
 	return nil
 }
 
+// scanBean 记录 bean 在属性绑定时涉及的属性元数据，包括构造函数的参数和 bean
+// 的字段，代码位置使用 bean 的注册点。
+func (c *Container) scanBean(b *BeanDefinition) {
+	catalog := c.p.Catalog()
+	if b.f != nil {
+		b.f.ForEachBind(func(t reflect.Type, tag string) {
+			_ = catalog.Scan(t, tag, b.FileLine())
+		})
+	}
+	if t := util.Indirect(b.Type()); t.Kind() == reflect.Struct {
+		_ = catalog.Scan(t, "${"+conf.RootKey+"}", b.FileLine())
+	}
+}
+
+// Catalog 返回属性元数据的目录，在容器刷新之前调用时会先扫描已经注册的 bean ，
+// 以及通过 ProvideForEach 注册的构造函数，后者的属性前缀为 key.* 。
+func (c *Container) Catalog() *conf.Catalog {
+	if c.state == Unrefreshed {
+		for _, b := range c.beans {
+			c.scanBean(b)
+		}
+		for _, e := range c.forEach {
+			args := append(e.args[:len(e.args):len(e.args)], arg.Prefix(e.key+".*"))
+			b := NewBean(e.ctor, args...)
+			b.file, b.line = e.file, e.line
+			c.scanBean(b)
+		}
+	}
+	return c.p.Catalog()
+}
+
 func (c *Container) registerBean(b *BeanDefinition) error {
 	if d, ok := c.beansById[b.ID()]; ok {
 		return fmt.Errorf("found duplicate beans [%s] [%s]", b, d)
@@ -2786,6 +2786,37 @@ func TestDefaultSpringContext(t *testing.T) {
 		assert.Error(t, err, "can't find bean, bean:\"\"")
 	})
 
+	t.Run("bean:test_ctx:dev,test", func(t *testing.T) {
+
+		c, ch := container()
+		c.Property(environ.SpringProfilesActive, "dev, test")
+		c.Object(&BeanZero{5}).On(cond.OnProfile("test"))
+		err := c.Refresh()
+		assert.Nil(t, err)
+
+		p := <-ch
+
+		var b *BeanZero
+		err = p.Get(&b)
+		assert.Nil(t, err)
+	})
+
+	t.Run("bean:test_ctx:group", func(t *testing.T) {
+
+		c, ch := container()
+		c.Property(environ.SpringProfilesActive, "prod")
+		c.Property(environ.SpringProfilesGroup+".prod", "prod-db,test")
+		c.Object(&BeanZero{5}).On(cond.OnProfile("test"))
+		err := c.Refresh()
+		assert.Nil(t, err)
+
+		p := <-ch
+
+		var b *BeanZero
+		err = p.Get(&b)
+		assert.Nil(t, err)
+	})
+
 	t.Run("option withClassName Condition", func(t *testing.T) {
 
 		c, ch := container()
@@ -3265,3 +3296,189 @@ func TestFind(t *testing.T) {
 	}
 	assert.Equal(t, count == 0, true)
 }
+
+type CatalogClient struct {
+	Addr    string        `value:"${addr:=127.0.0.1:6379}"`
+	Timeout time.Duration `value:"${timeout:=1s}"`
+}
+
+type CatalogService struct {
+	Name string `value:"${app.name:=demo}"`
+}
+
+func TestContainer_Catalog(t *testing.T) {
+
+	c := gs.New()
+	c.Object(new(CatalogService))
+	c.Provide(func(client CatalogClient) *int { return new(int) }, "${redis}")
+
+	metas := c.Catalog().Metas()
+	assert.Equal(t, len(metas), 3)
+	assert.Equal(t, metas[0].Key, "app.name")
+	assert.Equal(t, metas[0].Default, "demo")
+	assert.Equal(t, metas[1].Key, "redis.addr")
+	assert.Equal(t, metas[1].Default, "127.0.0.1:6379")
+	assert.Equal(t, metas[2].Key, "redis.timeout")
+	assert.Equal(t, metas[2].Type, "time.Duration")
+}
+
+type ValidatedService struct {
+	Port int    `value:"${validated.port}" validate:"min=1,max=65535"`
+	Mode string `value:"${validated.mode:=debug}" validate:"oneof=debug release"`
+}
+
+func TestContainer_Validate(t *testing.T) {
+	c := gs.New()
+	c.Property("validated.port", 0)
+	c.Property("validated.mode", "test")
+	c.Object(new(ValidatedService))
+	err := c.Refresh()
+	assert.Error(t, err, `property "validated.port" \(ValidatedService.Port\) violates min=1; property "validated.mode" \(ValidatedService.Mode\) violates oneof=debug release`)
+}
+
+type endpoint struct {
+	Host string
+	Port int
+}
+
+func TestContainer_Convert(t *testing.T) {
+	c := gs.New()
+	c.Convert(func(s string) (endpoint, error) {
+		ss := strings.Split(s, ":")
+		port, err := strconv.Atoi(ss[1])
+		return endpoint{Host: ss[0], Port: port}, err
+	})
+	c.Property("endpoint", "localhost:8080")
+	var config struct {
+		Endpoint endpoint `value:"${endpoint}"`
+	}
+	c.Object(&config)
+	err := c.Refresh()
+	assert.Nil(t, err)
+	assert.Equal(t, config.Endpoint, endpoint{Host: "localhost", Port: 8080})
+}
+
+func TestContainer_TypedProp(t *testing.T) {
+	c, ch := container()
+	c.Property("redis.host", "localhost")
+	c.Property("redis.port", 6379)
+	c.Property("redis.timeout", "3s")
+	c.Object(new(int)).On(cond.OnMatches(func(ctx cond.Context) (bool, error) {
+		p, ok := ctx.(cond.PropContext)
+		if !ok {
+			return false, nil
+		}
+		port, err := p.GetInt("redis.port")
+		return port == 6379 && p.HasPrefix("redis"), err
+	})).Name("redis")
+	err := c.Refresh()
+	assert.Nil(t, err)
+
+	p := <-ch
+	host, err := p.GetString("redis.host")
+	assert.Nil(t, err)
+	assert.Equal(t, host, "localhost")
+	timeout, err := p.GetDuration("redis.timeout")
+	assert.Nil(t, err)
+	assert.Equal(t, timeout, 3*time.Second)
+	assert.True(t, p.Has("redis.host"))
+	assert.False(t, p.Has("redis"))
+
+	sub := p.Sub("redis")
+	port, err := sub.GetInt("port")
+	assert.Nil(t, err)
+	assert.Equal(t, port, 6379)
+
+	var i *int
+	err = p.Get(&i, "redis")
+	assert.Nil(t, err)
+}
+
+type PrefixRedisConfig struct {
+	Host string `value:"${host:=127.0.0.1}"`
+	Port int    `value:"${port:=6379}"`
+}
+
+type PrefixRedisClient struct {
+	Addr string
+}
+
+func NewPrefixRedisClient(config PrefixRedisConfig) *PrefixRedisClient {
+	return &PrefixRedisClient{Addr: fmt.Sprintf("%s:%d", config.Host, config.Port)}
+}
+
+func TestContainer_Prefix(t *testing.T) {
+
+	t.Run("prefix", func(t *testing.T) {
+		c, ch := container()
+		c.Property("redis.session.host", "10.0.0.1")
+		c.Property("redis.cache.port", 6380)
+		c.Provide(NewPrefixRedisClient, arg.Prefix("redis.session")).Name("session")
+		c.Provide(NewPrefixRedisClient, arg.Prefix("redis.cache")).Name("cache")
+		c.Provide(func(timeout time.Duration) *time.Duration {
+			return &timeout
+		}, "${timeout:=1s}", arg.Prefix("redis.session"))
+		c.Property("redis.session.timeout", "3s")
+		err := c.Refresh()
+		assert.Nil(t, err)
+		p := <-ch
+
+		var session, cache *PrefixRedisClient
+		err = p.Get(&session, "session")
+		assert.Nil(t, err)
+		assert.Equal(t, session.Addr, "10.0.0.1:6379")
+		err = p.Get(&cache, "cache")
+		assert.Nil(t, err)
+		assert.Equal(t, cache.Addr, "127.0.0.1:6380")
+
+		var timeout *time.Duration
+		err = p.Get(&timeout)
+		assert.Nil(t, err)
+		assert.Equal(t, *timeout, 3*time.Second)
+	})
+
+	t.Run("for each", func(t *testing.T) {
+		c, ch := container()
+		c.Property("redis.instances.session.host", "10.0.0.1")
+		c.Property("redis.instances.cache.host", "10.0.0.2")
+		c.Property("redis.instances.cache.port", 6380)
+		c.ProvideForEach("redis.instances", NewPrefixRedisClient)
+		err := c.Refresh()
+		assert.Nil(t, err)
+		p := <-ch
+
+		var clients map[string]*PrefixRedisClient
+		err = p.Get(&clients)
+		assert.Nil(t, err)
+		assert.Equal(t, len(clients), 2)
+		assert.Equal(t, clients["session"].Addr, "10.0.0.1:6379")
+		assert.Equal(t, clients["cache"].Addr, "10.0.0.2:6380")
+	})
+
+	t.Run("for each catalog", func(t *testing.T) {
+		c := gs.New()
+		c.ProvideForEach("redis.instances", NewPrefixRedisClient)
+		catalog := c.Catalog()
+		assert.False(t, catalog.Unknown("redis.instances.cache.host"))
+		assert.False(t, catalog.Unknown("redis.instances.cache.port"))
+		assert.True(t, catalog.Unknown("redis.instances.cache.hots"))
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		type RedisConfig struct {
+			Host string `value:"${redis.host:=127.0.0.1}"`
+		}
+		c, ch := container()
+		c.Property("redis.host", "10.0.0.1")
+		c.Provide(func(config RedisConfig) *PrefixRedisClient {
+			return &PrefixRedisClient{Addr: config.Host}
+		})
+		err := c.Refresh()
+		assert.Nil(t, err)
+		p := <-ch
+		var client *PrefixRedisClient
+		err = p.Get(&client)
+		assert.Nil(t, err)
+		assert.Equal(t, client.Addr, "10.0.0.1")
+	})
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/gs/environ"
+	"github.com/go-spring/spring-core/log"
+)
+
+// loggingFile 日志文件的配置，绑定 logging.file 前缀的属性。
+type loggingFile struct {
+	Path     string        `value:"${path:=}"`          // 日志文件的路径，为空表示输出到控制台
+	Format   string        `value:"${format:=text}"`    // 日志格式，text 或者 json
+	MaxSize  conf.ByteSize `value:"${max-size:=10MB}"`  // 单个文件的最大字节数，0 表示不按照大小滚动
+	Rotation string        `value:"${rotation:=daily}"` // 按照时间滚动的周期，none 、daily 或者 hourly
+	MaxFiles int           `value:"${max-files:=7}"`    // 最多保留的滚动后的文件数，0 表示不限制
+	MaxAge   time.Duration `value:"${max-age:=0s}"`     // 滚动后的文件的最长保留时间，0 表示不限制
+	Compress bool          `value:"${compress:=false}"` // 是否使用 gzip 压缩滚动后的文件
+}
+
+// openLogFile 设置了 logging.file.path 属性时将日志改为写入滚动日志文件，日志文
+// 件注册为 bean ，由容器在关闭时销毁。
+func (app *App) openLogFile(p *conf.Properties) error {
+
+	var c loggingFile
+	if err := p.Bind(&c, conf.Key(environ.LoggingFile)); err != nil {
+		return err
+	}
+	if c.Path == "" {
+		return nil
+	}
+
+	rotation, err := log.ParseRotation(c.Rotation)
+	if err != nil {
+		return fmt.Errorf("%s.rotation: %w", environ.LoggingFile, err)
+	}
+
+	var output func(f *log.RollingFile) log.Output
+	switch strings.ToLower(c.Format) {
+	case "text":
+		output = func(f *log.RollingFile) log.Output { return log.Text(f) }
+	case "json":
+		output = func(f *log.RollingFile) log.Output { return log.JSON(f) }
+	default:
+		return fmt.Errorf("%s.format: invalid format %q", environ.LoggingFile, c.Format)
+	}
+
+	f, err := log.NewRollingFile(c.Path,
+		log.MaxSize(int64(c.MaxSize)),
+		log.Rotate(rotation),
+		log.MaxFiles(c.MaxFiles),
+		log.MaxAge(c.MaxAge),
+		log.Compress(c.Compress))
+	if err != nil {
+		return err
+	}
+
+	log.SetOutput(output(f))
+	app.Object(f).Destroy(closeLogFile)
+	return nil
+}
+
+// closeLogFile 将日志恢复为输出到控制台，然后关闭日志文件。
+func closeLogFile(f *log.RollingFile) error {
+	log.SetOutput(log.Console)
+	return f.Close()
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/gs/arg"
@@ -36,9 +37,22 @@ import (
 // 出一个可共用的接口来，也就是说，无论程序是 Container 方式启动还是 App 方式启动，
 // 都可以在需要使用这些方法的地方注入一个 Pandora 对象而不是 Container 对象或者
 // App 对象，从而实现使用方式的统一。
+// Pandora 由框架实现，之后的版本可能会为它增加新的方法，自行实现该接口的代码需要
+// 随之修改，测试时可以通过内嵌 Pandora 接口来保持兼容。
 type Pandora interface {
 	Go(fn func(ctx context.Context))
 	Prop(key string, opts ...conf.GetOption) interface{}
+	GetString(key string, opts ...conf.GetOption) (string, error)
+	GetInt(key string, opts ...conf.GetOption) (int, error)
+	GetBool(key string, opts ...conf.GetOption) (bool, error)
+	GetDuration(key string, opts ...conf.GetOption) (time.Duration, error)
+	GetStringSlice(key string, opts ...conf.GetOption) ([]string, error)
+	GetStringMap(key string, opts ...conf.GetOption) (map[string]string, error)
+	Has(key string) bool
+	HasPrefix(prefix string) bool
+	Sub(prefix string) *conf.Properties
+	Origins(key string) []conf.Origin
+	Snapshot() *conf.Properties
 	Bind(i interface{}, opts ...conf.BindOption) error
 	Get(i interface{}, selectors ...bean.Selector) error
 	Wire(objOrCtor interface{}, ctorArgs ...arg.Arg) (interface{}, error)
@@ -61,6 +75,70 @@ func (p *pandora) Prop(key string, opts ...conf.GetOption) interface{} {
 	return p.c.p.Get(key, opts...)
 }
 
+// GetString 返回 key 对应的 string 类型的属性值，属性值不存在时返回 conf.Def
+// 设置的默认值，没有默认值时返回错误。
+func (p *pandora) GetString(key string, opts ...conf.GetOption) (string, error) {
+	return p.c.p.GetString(key, opts...)
+}
+
+// GetInt 返回 key 对应的 int 类型的属性值，属性值不存在时返回 conf.Def 设置的
+// 默认值，没有默认值或者类型转换失败时返回错误。
+func (p *pandora) GetInt(key string, opts ...conf.GetOption) (int, error) {
+	return p.c.p.GetInt(key, opts...)
+}
+
+// GetBool 返回 key 对应的 bool 类型的属性值，属性值不存在时返回 conf.Def 设置
+// 的默认值，没有默认值或者类型转换失败时返回错误。
+func (p *pandora) GetBool(key string, opts ...conf.GetOption) (bool, error) {
+	return p.c.p.GetBool(key, opts...)
+}
+
+// GetDuration 返回 key 对应的 time.Duration 类型的属性值，属性值不存在时返回
+// conf.Def 设置的默认值，没有默认值或者类型转换失败时返回错误。
+func (p *pandora) GetDuration(key string, opts ...conf.GetOption) (time.Duration, error) {
+	return p.c.p.GetDuration(key, opts...)
+}
+
+// GetStringSlice 返回 key 对应的 []string 类型的属性值，属性值可以是列表也可以
+// 是逗号分隔的字符串，属性值不存在时返回 conf.Def 设置的默认值。
+func (p *pandora) GetStringSlice(key string, opts ...conf.GetOption) ([]string, error) {
+	return p.c.p.GetStringSlice(key, opts...)
+}
+
+// GetStringMap 返回 key 对应的 map[string]string 类型的属性值，属性值不存在时
+// 返回 conf.Def 设置的默认值。
+func (p *pandora) GetStringMap(key string, opts ...conf.GetOption) (map[string]string, error) {
+	return p.c.p.GetStringMap(key, opts...)
+}
+
+// Has 返回 key 对应的属性值是否存在。
+func (p *pandora) Has(key string) bool {
+	return p.c.p.Has(key)
+}
+
+// HasPrefix 返回是否存在 prefix.sub 或者 prefix[i] 形式的子属性。
+func (p *pandora) HasPrefix(prefix string) bool {
+	return p.c.p.HasPrefix(prefix)
+}
+
+// Sub 返回以 prefix 为根的属性列表，可以将一部分属性交给只关心这部分属性的代码
+// 使用，比如 starter 可以将自己的属性交给构造函数。
+func (p *pandora) Sub(prefix string) *conf.Properties {
+	return p.c.p.Sub(prefix)
+}
+
+// Origins 返回 key 对应的属性值的所有来源，按照设置的先后顺序排列，最后一个是当
+// 前生效的来源，之前的都是被覆盖的来源，比如配置文件中的值被环境变量覆盖。
+func (p *pandora) Origins(key string) []conf.Origin {
+	return p.c.p.Origins(key)
+}
+
+// Snapshot 返回当前属性列表的快照，快照不受之后的属性刷新的影响，一次请求中多次
+// 读取属性时可以使用快照获得一致的视图。
+func (p *pandora) Snapshot() *conf.Properties {
+	return p.c.p.Snapshot()
+}
+
 // Bind 将 key 对应的属性值绑定到某个数据类型的实例上。i 必须是一个指针，只有这
 // 样才能将修改传递出去。注意该方法不会进行依赖注入，Wire 方法才会。
 func (p *pandora) Bind(i interface{}, opts ...conf.BindOption) error {
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/spring-core/log"
+)
+
+// writePidFile 将当前进程的 ID 写入 file 文件。文件使用 O_EXCL 方式创建，因此
+// 同时启动的多个实例只有一个能够创建成功。如果 file 文件已经存在并且记录的进程仍
+// 然存活则返回 error ，以防止同一个应用被启动多次；记录的进程已经退出时说明这是
+// 上次异常退出时残留的文件，删除之后重新创建；无法解析的文件可能正在被其他实例写
+// 入，也返回 error 。
+func writePidFile(file string) error {
+
+	err := createPidFile(file)
+	if !os.IsExist(err) {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return fmt.Errorf("invalid pid file %s", file)
+	}
+	if pid == os.Getpid() {
+		return nil
+	}
+	if processAlive(pid) {
+		return fmt.Errorf("process %d is already running, pid file %s", pid, file)
+	}
+
+	log.Warnf("remove stale pid file %s of process %d", file, pid)
+	if err = os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err = createPidFile(file); os.IsExist(err) {
+		return fmt.Errorf("pid file %s is created by another process", file)
+	}
+	return err
+}
+
+// createPidFile 创建 file 文件并写入当前进程的 ID ，文件已经存在时返回的 error
+// 满足 os.IsExist 。
+func createPidFile(file string) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// removePidFile 删除 file 文件，但只删除记录的是当前进程 ID 的文件。
+func removePidFile(file string) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if strings.TrimSpace(string(b)) != strconv.Itoa(os.Getpid()) {
+		return nil
+	}
+	return os.Remove(file)
+}
+
+// dumpGoroutines 返回所有 goroutine 的调用栈。
+func dumpGoroutines() string {
+	buf := bytes.NewBuffer(nil)
+	_ = pprof.Lookup("goroutine").WriteTo(buf, 2)
+	return buf.String()
+}
+
+// dumpBeans 返回所有 bean 的状态，容器在刷新后会释放 bean 的元数据，除非开启了
+// enable-pandora 属性。
+func (c *Container) dumpBeans() string {
+	if c.beans == nil {
+		return "bean definitions have been released, set enable-pandora=true to keep them"
+	}
+	buf := bytes.NewBuffer(nil)
+	for _, b := range c.beans {
+		_, _ = fmt.Fprintf(buf, "%s status:%s\n", b, b.status)
+	}
+	return buf.String()
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// processAlive 返回 pid 对应的进程是否存活。
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// notifySignals 监听 SIGUSR1 信号用于输出诊断信息，reload 为 true 时还监听
+// SIGHUP 信号用于触发重载回调。
+func notifySignals(ch chan<- os.Signal, reload bool) {
+	if reload {
+		signal.Notify(ch, syscall.SIGHUP, syscall.SIGUSR1)
+	} else {
+		signal.Notify(ch, syscall.SIGUSR1)
+	}
+}
+
+func isReloadSignal(sig os.Signal) bool { return sig == syscall.SIGHUP }
+
+func isDumpSignal(sig os.Signal) bool { return sig == syscall.SIGUSR1 }
@@ -0,0 +1,65 @@
+//go:build !windows
+// +build !windows
+
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/log"
+	"github.com/go-spring/spring-stl/assert"
+)
+
+func TestHandleSignal(t *testing.T) {
+
+	var buf bytes.Buffer
+	log.SetOutput(log.Text(&buf))
+	defer log.Reset()
+
+	var reloads []string
+	app := gs.NewApp()
+	app.OnReload(func(ctx gs.AppContext) error {
+		reloads = append(reloads, "a")
+		return nil
+	})
+	app.OnReload(func(ctx gs.AppContext) error {
+		reloads = append(reloads, "b")
+		return errors.New("b failed")
+	})
+
+	app.HandleSignal(nil, syscall.SIGHUP)
+	assert.Equal(t, reloads, []string{"a", "b"})
+	assert.True(t, strings.Contains(buf.String(), "reload error: b failed"))
+
+	buf.Reset()
+	app.HandleSignal(nil, syscall.SIGUSR1)
+	assert.Equal(t, reloads, []string{"a", "b"})
+	assert.True(t, strings.Contains(buf.String(), "goroutines:"))
+	assert.True(t, strings.Contains(buf.String(), "TestHandleSignal"))
+	assert.True(t, strings.Contains(buf.String(), "beans:"))
+
+	buf.Reset()
+	app.HandleSignal(nil, syscall.SIGINT)
+	assert.Equal(t, buf.Len(), 0)
+}
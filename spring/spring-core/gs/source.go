@@ -0,0 +1,98 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-spring/spring-core/conf"
+)
+
+// ConfigSource 配置源，比如公司内部的配置中心。配置源的属性优先级高于配置文件，
+// 低于环境变量和命令行参数，多个配置源按照注册的顺序排列，排在后面的优先级更高。
+// conf/remote 包提供了通过 HTTP 轮询获取配置的实现。
+type ConfigSource interface {
+
+	// Name 返回配置源的名称，用于记录属性值的来源。
+	Name() string
+
+	// Load 返回配置源中的全部属性，应用启动时调用，返回错误时应用启动失败。
+	Load(ctx context.Context) (map[string]string, error)
+
+	// Watch 返回配置变化的通知，每次通知都包含全部属性，ctx 结束时关闭 channel 。
+	Watch(ctx context.Context) (<-chan map[string]string, error)
+}
+
+// sourceProperties 将配置源的属性转换成属性列表，并且记录属性值的来源。
+func sourceProperties(s ConfigSource, m map[string]string) *conf.Properties {
+	p := conf.New()
+	for k, v := range m {
+		p.Set(k, v, conf.Source(s.Name()))
+	}
+	return p
+}
+
+// configLayers 按照优先级从低到高保存各个来源的属性，任何一层发生变化后重新合并
+// 所有的层，然后整体替换 target 中的属性。上次合并之后直接写入 target 的属性，比
+// 如启动后通过 App.Property 或者 Properties.Set 设置的属性，保存在优先级最高的
+// live 层中，不会因为重新合并而丢失。
+type configLayers struct {
+	mutex  sync.Mutex
+	target *conf.Properties
+	layers []*conf.Properties
+	merged *conf.Properties // 上次合并的结果
+	live   *conf.Properties // 合并之后直接写入 target 的属性
+}
+
+// update 替换第 i 层的属性，然后使用发生变化的属性名调用 notify 。
+func (l *configLayers) update(i int, p *conf.Properties, notify func(keys []string)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.saveLiveWrites()
+	l.layers[i] = p
+	q := conf.New()
+	for _, layer := range l.layers {
+		q.Merge(layer)
+	}
+	if l.live != nil {
+		q.Merge(l.live)
+	}
+	notify(l.target.Reload(q))
+	l.merged = l.target.Snapshot()
+}
+
+// saveLiveWrites 将上次合并之后直接写入 target 的属性保存到 live 层中。
+func (l *configLayers) saveLiveWrites() {
+	if l.merged == nil {
+		return
+	}
+	prev := l.merged.Dump()
+	for k, v := range l.target.Dump() {
+		if old, ok := prev[k]; ok && old == v {
+			continue
+		}
+		if l.live == nil {
+			l.live = conf.New()
+		}
+		var opts []conf.SetOption
+		if origins := l.target.Origins(k); len(origins) > 0 {
+			opts = append(opts, conf.Source(origins[len(origins)-1].Source))
+		}
+		l.live.Set(k, v, opts...)
+	}
+}
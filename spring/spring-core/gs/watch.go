@@ -0,0 +1,128 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/go-spring/spring-core/log"
+)
+
+// fileSet 加载配置时涉及的文件和目录。
+type fileSet map[string]struct{}
+
+func (s fileSet) add(file string) {
+	if s != nil {
+		s[file] = struct{}{}
+	}
+}
+
+// fileStamp 文件的修改时间和大小，文件不存在时 exists 为 false 。
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// stampFiles 返回所有文件的当前状态，os.Stat 跟随符号链接，所以 Kubernetes 通过
+// 替换 ..data 链接更新的 ConfigMap 也能被发现。
+func stampFiles(files fileSet) map[string]fileStamp {
+	m := make(map[string]fileStamp, len(files))
+	for file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			m[file] = fileStamp{exists: true, size: fi.Size(), modTime: fi.ModTime()}
+		} else {
+			m[file] = fileStamp{}
+		}
+	}
+	return m
+}
+
+// configWatcher 通过轮询的方式监视配置文件的变化，发现变化后调用 reload 重新加载
+// 配置，reload 返回新的配置所涉及的文件和目录。
+type configWatcher struct {
+	interval time.Duration
+	stamps   map[string]fileStamp
+	reload   func() (fileSet, error)
+}
+
+// newConfigWatcher 返回一个 configWatcher 对象，stamps 是加载配置时文件的状态。
+func newConfigWatcher(interval time.Duration, stamps map[string]fileStamp, reload func() (fileSet, error)) *configWatcher {
+	return &configWatcher{
+		interval: interval,
+		stamps:   stamps,
+		reload:   reload,
+	}
+}
+
+func (w *configWatcher) watch(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 检查文件是否发生变化，发生变化时重新加载配置。加载失败时只输出日志，直到
+// 文件再次发生变化时才会重试。
+func (w *configWatcher) check() {
+
+	files := make(fileSet, len(w.stamps))
+	for file := range w.stamps {
+		files.add(file)
+	}
+
+	stamps := stampFiles(files)
+	if !stampsChanged(w.stamps, stamps) {
+		return
+	}
+	w.stamps = stamps
+
+	files, err := w.reload()
+	if err != nil {
+		log.Errorf("reload config error: %v", err)
+		return
+	}
+
+	// 加载期间文件可能再次发生变化，使用加载前的状态可以在下次检查时发现。
+	next := stampFiles(files)
+	for file, s := range stamps {
+		if _, ok := next[file]; ok {
+			next[file] = s
+		}
+	}
+	w.stamps = next
+}
+
+func stampsChanged(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return true
+	}
+	for file, s := range a {
+		if t, ok := b[file]; !ok || s.exists != t.exists || s.size != t.size || !s.modTime.Equal(t.modTime) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// Field 结构化日志的字段。
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String 返回字段值的字符串形式。
+func (f Field) String() string {
+	switch v := f.Value.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(f.Value)
+}
+
+// Any 创建任意类型的字段。
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, Value: val}
+}
+
+// String 创建 string 类型的字段。
+func String(key string, val string) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Int 创建 int 类型的字段。
+func Int(key string, val int) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Int64 创建 int64 类型的字段。
+func Int64(key string, val int64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Uint64 创建 uint64 类型的字段。
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Float64 创建 float64 类型的字段。
+func Float64(key string, val float64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Bool 创建 bool 类型的字段。
+func Bool(key string, val bool) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Duration 创建 time.Duration 类型的字段，输出为 1.5s 这样的格式。
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Time 创建 time.Time 类型的字段，输出为 RFC3339 格式。
+func Time(key string, val time.Time) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Err 创建 key 为 error 的字段，输出为 err.Error() 。
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// appendFields 将 Field 或者交替出现的 key 和 value 追加到 fields 中，缺少
+// value 的 key 的值为 nil 。
+func appendFields(fields []Field, kv []interface{}) []Field {
+	for i := 0; i < len(kv); i++ {
+		if f, ok := kv[i].(Field); ok {
+			fields = append(fields, f)
+			continue
+		}
+		f := Field{Key: fmt.Sprint(kv[i])}
+		if i+1 < len(kv) {
+			i++
+			f.Value = kv[i]
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
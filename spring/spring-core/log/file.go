@@ -0,0 +1,369 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation 日志文件按照时间滚动的周期。
+type Rotation int
+
+const (
+	RotateNone   = Rotation(0) // 不按照时间滚动
+	RotateDaily  = Rotation(1) // 每天滚动
+	RotateHourly = Rotation(2) // 每小时滚动
+)
+
+// ParseRotation 解析 none 、daily 、hourly 形式的滚动周期，空字符串表示 none 。
+func ParseRotation(s string) (Rotation, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return RotateNone, nil
+	case "daily":
+		return RotateDaily, nil
+	case "hourly":
+		return RotateHourly, nil
+	}
+	return RotateNone, fmt.Errorf("invalid rotation %q", s)
+}
+
+// period 返回 t 所在滚动周期的开始时间。
+func (r Rotation) period(t time.Time) time.Time {
+	switch r {
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// backupTimeFormat 滚动后的文件名中的时间格式，比如 app-2021-08-01T15-04-05.000.log 。
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RollingOption RollingFile 的可选项。
+type RollingOption func(f *RollingFile)
+
+// MaxSize 单个日志文件的最大字节数，超过后滚动，小于等于 0 表示不按照大小滚动。
+func MaxSize(size int64) RollingOption {
+	return func(f *RollingFile) { f.maxSize = size }
+}
+
+// Rotate 日志文件按照时间滚动的周期。
+func Rotate(r Rotation) RollingOption {
+	return func(f *RollingFile) { f.rotation = r }
+}
+
+// MaxFiles 最多保留的滚动后的文件数，小于等于 0 表示不限制。
+func MaxFiles(n int) RollingOption {
+	return func(f *RollingFile) { f.maxFiles = n }
+}
+
+// MaxAge 滚动后的文件的最长保留时间，小于等于 0 表示不限制。
+func MaxAge(d time.Duration) RollingOption {
+	return func(f *RollingFile) { f.maxAge = d }
+}
+
+// Compress 是否使用 gzip 压缩滚动后的文件。
+func Compress(compress bool) RollingOption {
+	return func(f *RollingFile) { f.compress = compress }
+}
+
+// RollingFile 可以按照大小和时间滚动的日志文件，滚动后的文件和当前文件位于同一
+// 目录，文件名中包含滚动的时间。压缩和清理滚动后的文件在后台进行。RollingFile
+// 可以并发使用，通常配合 Text 或者 JSON 作为 Output 的输出目标。
+type RollingFile struct {
+	path     string
+	maxSize  int64
+	rotation Rotation
+	maxFiles int
+	maxAge   time.Duration
+	compress bool
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	period time.Time
+	closed bool
+
+	millMu sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// NewRollingFile 创建滚动日志文件，文件所在的目录不存在时会自动创建，文件已经存
+// 在时追加写入。
+func NewRollingFile(path string, opts ...RollingOption) (*RollingFile, error) {
+	f := &RollingFile{path: path}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open 打开日志文件，已经存在的文件根据最后修改时间确定所在的滚动周期。
+func (f *RollingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.period = f.rotation.period(time.Now())
+	if f.size > 0 {
+		f.period = f.rotation.period(info.ModTime())
+	}
+	return nil
+}
+
+// Write 写入日志，写入前检查是否需要滚动。
+func (f *RollingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	now := time.Now()
+	timeUp := f.rotation != RotateNone && !f.rotation.period(now).Equal(f.period)
+	sizeUp := f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize
+	if timeUp || sizeUp {
+		// 滚动失败时继续写入当前文件，这里不能使用日志函数，否则会重入当前的锁。
+		if err := f.rotate(now); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "rotate log file %s error: %v\n", f.path, err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即滚动日志文件，比如收到外部工具的通知时。
+func (f *RollingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate(time.Now())
+}
+
+// rotate 将当前文件重命名为滚动后的文件然后打开新的文件，任何一步失败时都保持
+// 使用原来的文件句柄，因此滚动失败不会导致日志无法写入。
+func (f *RollingFile) rotate(now time.Time) error {
+
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	backup := prefix + now.Format(backupTimeFormat) + ext
+	for i := 1; exists(backup) || exists(backup+".gz"); i++ {
+		backup = fmt.Sprintf("%s%s.%d%s", prefix, now.Format(backupTimeFormat), i, ext)
+	}
+
+	if err := os.Rename(f.path, backup); err != nil {
+		return err
+	}
+
+	old := f.file
+	if err := f.open(); err != nil {
+		if e := os.Rename(backup, f.path); e != nil {
+			return fmt.Errorf("%w; restore %s error: %v", err, f.path, e)
+		}
+		return err
+	}
+	f.period = f.rotation.period(now)
+	_ = old.Close()
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.mill(now)
+	}()
+	return nil
+}
+
+func exists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+// mill 压缩滚动后的文件，然后删除超出数量或者过期的文件。
+func (f *RollingFile) mill(now time.Time) {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	backups, err := f.backups()
+	if err != nil {
+		Errorf("list log backups of %s error: %v", f.path, err)
+		return
+	}
+
+	var remove []backupFile
+	if f.maxFiles > 0 && len(backups) > f.maxFiles {
+		remove = append(remove, backups[f.maxFiles:]...)
+		backups = backups[:f.maxFiles]
+	}
+	if f.maxAge > 0 {
+		var keep []backupFile
+		for _, b := range backups {
+			if now.Sub(b.time) > f.maxAge {
+				remove = append(remove, b)
+			} else {
+				keep = append(keep, b)
+			}
+		}
+		backups = keep
+	}
+
+	for _, b := range remove {
+		if err = os.Remove(b.path); err != nil {
+			Errorf("remove log backup %s error: %v", b.path, err)
+		}
+	}
+
+	if !f.compress {
+		return
+	}
+	for _, b := range backups {
+		if strings.HasSuffix(b.path, ".gz") {
+			continue
+		}
+		if err = gzipFile(b.path); err != nil {
+			Errorf("compress log backup %s error: %v", b.path, err)
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	time time.Time
+	seq  int // 同一时间滚动多次时的序号
+}
+
+// backups 返回所有滚动后的文件，按照滚动时间从新到旧排列。
+func (f *RollingFile) backups() ([]backupFile, error) {
+
+	dir := filepath.Dir(f.path)
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []backupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		s := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz")
+		if !strings.HasSuffix(s, ext) || len(s) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, s[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		seq := 0
+		if n := strings.TrimSuffix(s[len(backupTimeFormat):], ext); n != "" {
+			if seq, err = strconv.Atoi(strings.TrimPrefix(n, ".")); err != nil || n[0] != '.' {
+				continue
+			}
+		}
+		ret = append(ret, backupFile{path: filepath.Join(dir, name), time: t, seq: seq})
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].time.Equal(ret[j].time) {
+			return ret[i].seq > ret[j].seq
+		}
+		return ret[i].time.After(ret[j].time)
+	})
+	return ret, nil
+}
+
+// gzipFile 将 file 压缩为 file.gz ，成功后删除 file 。
+func gzipFile(file string) (err error) {
+
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(file+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(file + ".gz")
+		}
+	}()
+
+	w := gzip.NewWriter(dst)
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(file)
+}
+
+// Close 关闭日志文件，并且等待后台的压缩和清理完成。
+func (f *RollingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	err := f.file.Close()
+	f.mu.Unlock()
+	f.wg.Wait()
+	return err
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JSON 返回将日志编码为 JSON 行写入 w 的 Output ，每行包含 time 、level 、
+// caller 、tag 、msg 、trace_id 、span_id 以及 fields 等字段，值为空的字段
+// 不输出。返回的 Output 可以并发使用。
+func JSON(w io.Writer) Output {
+	var mu sync.Mutex
+	return func(skip int, level Level, e *Entry) {
+		_, file, line, _ := runtime.Caller(skip + 1)
+		b := encodeJSON(time.Now(), level, file+":"+strconv.Itoa(line), e)
+		mu.Lock()
+		_, _ = w.Write(b)
+		mu.Unlock()
+		terminate(level, e)
+	}
+}
+
+func encodeJSON(t time.Time, level Level, caller string, e *Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJSON(&buf, t.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSON(&buf, level.String())
+	buf.WriteString(`,"caller":`)
+	writeJSON(&buf, caller)
+	if e.tag != "" {
+		buf.WriteString(`,"tag":`)
+		writeJSON(&buf, e.tag)
+	}
+	buf.WriteString(`,"msg":`)
+	writeJSON(&buf, e.msg)
+	if sc := e.SpanContext(); sc.IsValid() {
+		buf.WriteString(`,"trace_id":`)
+		writeJSON(&buf, sc.TraceID.String())
+		buf.WriteString(`,"span_id":`)
+		writeJSON(&buf, sc.SpanID.String())
+	}
+	if len(e.fields) > 0 {
+		buf.WriteString(`,"fields":{`)
+		for i, f := range e.fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(&buf, f.Key)
+			buf.WriteByte(':')
+			writeJSONValue(&buf, f)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	b, _ := json.Marshal(v)
+	buf.Write(b)
+}
+
+// writeJSONValue 输出字段值，error 和 time.Duration 等类型按照字符串输出，不能
+// 编码为 JSON 的值也按照字符串输出。
+func writeJSONValue(buf *bytes.Buffer, f Field) {
+	switch f.Value.(type) {
+	case error, time.Duration, time.Time:
+		writeJSON(buf, f.String())
+		return
+	}
+	b, err := json.Marshal(f.Value)
+	if err != nil {
+		writeJSON(buf, f.String())
+		return
+	}
+	buf.Write(b)
+}
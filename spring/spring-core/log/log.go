@@ -20,10 +20,14 @@ package log
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/go-spring/spring-core/trace"
 )
 
 const (
@@ -71,11 +75,18 @@ func Tag(tag string) Entry {
 	return empty.Tag(tag)
 }
 
+// With 创建包含结构化字段的 Entry ，参数可以是 Field 也可以是交替出现的 key 和
+// value ，比如 log.With("user", id).Info("login") 。
+func With(kv ...interface{}) Entry {
+	return empty.With(kv...)
+}
+
 // Entry 打包需要记录的日志信息。
 type Entry struct {
-	ctx context.Context
-	tag string
-	msg string
+	ctx    context.Context
+	tag    string
+	msg    string
+	fields []Field
 }
 
 func (e *Entry) GetMsg() string {
@@ -90,6 +101,15 @@ func (e *Entry) GetCtx() context.Context {
 	return e.ctx
 }
 
+func (e *Entry) GetFields() []Field {
+	return e.fields
+}
+
+// SpanContext 返回 ctx 中当前活跃的 span 的链路信息，没有时返回无效值。
+func (e *Entry) SpanContext() trace.SpanContext {
+	return trace.SpanContextFromContext(e.ctx)
+}
+
 func (e Entry) Tag(tag string) Entry {
 	e.tag = tag
 	return e
@@ -100,6 +120,12 @@ func (e Entry) Ctx(ctx context.Context) Entry {
 	return e
 }
 
+// With 返回追加了结构化字段的 Entry ，原 Entry 不受影响。
+func (e Entry) With(kv ...interface{}) Entry {
+	e.fields = appendFields(e.fields[:len(e.fields):len(e.fields)], kv)
+	return e
+}
+
 func (e Entry) print(a ...interface{}) *Entry {
 	e.msg = fmt.Sprint(a...)
 	return &e
@@ -124,8 +150,38 @@ func Console(skip int, level Level, e *Entry) {
 	}
 
 	_, file, line, _ := runtime.Caller(skip + 1)
-	_, _ = fmt.Printf("[%s] %s:%d %s\n", strLevel, file, line, e.GetMsg())
+	_, _ = fmt.Println(textLine(strLevel, file, line, e))
+	terminate(level, e)
+}
+
+// Text 返回将日志按照文本格式写入 w 的 Output ，格式和 Console 相同但是没有颜色
+// 并且每行以时间开头。返回的 Output 可以并发使用。
+func Text(w io.Writer) Output {
+	var mu sync.Mutex
+	return func(skip int, level Level, e *Entry) {
+		_, file, line, _ := runtime.Caller(skip + 1)
+		strLevel := strings.ToUpper(level.String())
+		s := time.Now().Format("2006-01-02T15:04:05.000Z07:00") + " " + textLine(strLevel, file, line, e) + "\n"
+		mu.Lock()
+		_, _ = io.WriteString(w, s)
+		mu.Unlock()
+		terminate(level, e)
+	}
+}
+
+func textLine(strLevel string, file string, line int, e *Entry) string {
+	msg := e.GetMsg()
+	for _, f := range e.fields {
+		msg += " " + f.Key + "=" + f.String()
+	}
+	if sc := e.SpanContext(); sc.IsValid() {
+		return fmt.Sprintf("[%s] %s:%d trace_id=%s span_id=%s %s", strLevel, file, line, sc.TraceID, sc.SpanID, msg)
+	}
+	return fmt.Sprintf("[%s] %s:%d %s", strLevel, file, line, msg)
+}
 
+// terminate 在输出 PANIC 和 FATAL 级别的日志后分别执行 panic 和退出程序。
+func terminate(level Level, e *Entry) {
 	switch level {
 	case PanicLevel:
 		panic(e.GetMsg())
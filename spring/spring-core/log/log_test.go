@@ -17,14 +17,25 @@
 package log_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-spring/spring-core/log"
+	"github.com/go-spring/spring-core/trace"
+	"github.com/go-spring/spring-stl/assert"
 )
 
 func TestDefault(t *testing.T) {
@@ -171,3 +182,224 @@ func TestEntry(t *testing.T) {
 	logger.Ctx(ctx).Fatal("level:", "fatal")
 	logger.Ctx(ctx).Fatalf("level:%s", "fatal")
 }
+
+func TestWith(t *testing.T) {
+
+	e := log.With("user", 42, log.Err(errors.New("denied")), "dangling")
+	fields := e.GetFields()
+	assert.Equal(t, fields, []log.Field{
+		log.Any("user", 42),
+		log.Err(errors.New("denied")),
+		{Key: "dangling"},
+	})
+
+	a := e.With(log.String("a", "1"))
+	b := e.With(log.String("b", "2"))
+	assert.Equal(t, len(e.GetFields()), 3)
+	assert.Equal(t, a.GetFields()[3], log.String("a", "1"))
+	assert.Equal(t, b.GetFields()[3], log.String("b", "2"))
+
+	assert.Equal(t, log.Duration("d", 1500*time.Millisecond).String(), "1.5s")
+	assert.Equal(t, log.Bool("ok", true).String(), "true")
+}
+
+func TestJSON(t *testing.T) {
+
+	var buf bytes.Buffer
+	log.SetOutput(log.JSON(&buf))
+	defer log.Reset()
+
+	ctx, span := trace.Start(context.Background(), "test")
+	defer span.End()
+
+	log.Ctx(ctx).Tag("__in").With("user", 42, log.Duration("cost", time.Second), log.Err(errors.New("denied"))).Warn("login failed")
+	log.Info("hello")
+	log.Debug("invisible")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 2)
+
+	var m map[string]interface{}
+	err := json.Unmarshal([]byte(lines[0]), &m)
+	assert.Nil(t, err)
+	assert.Equal(t, m["level"], "warn")
+	assert.Equal(t, m["tag"], "__in")
+	assert.Equal(t, m["msg"], "login failed")
+	assert.Equal(t, m["trace_id"], span.TraceID())
+	assert.Equal(t, m["span_id"], span.SpanID())
+	assert.Equal(t, m["fields"], map[string]interface{}{
+		"user":  float64(42),
+		"cost":  "1s",
+		"error": "denied",
+	})
+	assert.True(t, strings.Contains(m["caller"].(string), "log_test.go:"))
+	_, err = time.Parse(time.RFC3339Nano, m["time"].(string))
+	assert.Nil(t, err)
+
+	m = nil
+	err = json.Unmarshal([]byte(lines[1]), &m)
+	assert.Nil(t, err)
+	assert.Equal(t, len(m), 4)
+	assert.Equal(t, m["msg"], "hello")
+}
+
+func TestRollingFile(t *testing.T) {
+
+	listFiles := func(dir string) []string {
+		infos, err := ioutil.ReadDir(dir)
+		assert.Nil(t, err)
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("size", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "log")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "app.log")
+		f, err := log.NewRollingFile(path, log.MaxSize(10), log.MaxFiles(2), log.Compress(true))
+		assert.Nil(t, err)
+		for _, s := range []string{"1111111\n", "2222222\n", "3333333\n", "4444444\n"} {
+			_, err = f.Write([]byte(s))
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, f.Close())
+
+		_, err = f.Write([]byte("closed\n"))
+		assert.Equal(t, err, os.ErrClosed)
+
+		b, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), "4444444\n")
+
+		var backups []string
+		for _, name := range listFiles(dir) {
+			if name == "app.log" {
+				continue
+			}
+			assert.True(t, strings.HasPrefix(name, "app-"))
+			assert.True(t, strings.HasSuffix(name, ".log.gz"))
+			r, err := os.Open(filepath.Join(dir, name))
+			assert.Nil(t, err)
+			gr, err := gzip.NewReader(r)
+			assert.Nil(t, err)
+			b, err = ioutil.ReadAll(gr)
+			assert.Nil(t, err)
+			_ = r.Close()
+			backups = append(backups, string(b))
+		}
+		sort.Strings(backups)
+		assert.Equal(t, backups, []string{"2222222\n", "3333333\n"})
+	})
+
+	t.Run("rotate failed", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "log")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "app.log")
+		f, err := log.NewRollingFile(path, log.MaxSize(10))
+		assert.Nil(t, err)
+		defer f.Close()
+
+		_, err = f.Write([]byte("1111111\n"))
+		assert.Nil(t, err)
+
+		// 文件被外部删除后重命名失败，日志继续写入原来的文件句柄。
+		assert.Nil(t, os.Remove(path))
+		for i := 0; i < 2; i++ {
+			n, err := f.Write([]byte("2222222\n"))
+			assert.Nil(t, err)
+			assert.Equal(t, n, 8)
+		}
+
+		// 文件恢复后可以正常滚动。
+		assert.Nil(t, ioutil.WriteFile(path, []byte("3333333\n"), 0644))
+		_, err = f.Write([]byte("4444444\n"))
+		assert.Nil(t, err)
+		b, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), "4444444\n")
+	})
+
+	t.Run("time", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "log")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "app.log")
+		err = ioutil.WriteFile(path, []byte("yesterday\n"), 0644)
+		assert.Nil(t, err)
+		yesterday := time.Now().Add(-24 * time.Hour)
+		assert.Nil(t, os.Chtimes(path, yesterday, yesterday))
+
+		old := filepath.Join(dir, "app-"+time.Now().Add(-72*time.Hour).Format("2006-01-02T15-04-05.000")+".log")
+		err = ioutil.WriteFile(old, []byte("expired\n"), 0644)
+		assert.Nil(t, err)
+
+		f, err := log.NewRollingFile(path, log.Rotate(log.RotateDaily), log.MaxAge(48*time.Hour))
+		assert.Nil(t, err)
+		_, err = f.Write([]byte("today\n"))
+		assert.Nil(t, err)
+		assert.Nil(t, f.Close())
+
+		b, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), "today\n")
+
+		names := listFiles(dir)
+		assert.Equal(t, len(names), 2)
+		b, err = ioutil.ReadFile(filepath.Join(dir, names[0]))
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), "yesterday\n")
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "log")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		f, err := log.NewRollingFile(filepath.Join(dir, "app.log"), log.MaxSize(1024))
+		assert.Nil(t, err)
+		log.SetOutput(log.JSON(f))
+		defer log.Reset()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					log.With("goroutine", i, "n", j).Info("concurrent")
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Nil(t, f.Close())
+
+		lines := 0
+		for _, name := range listFiles(dir) {
+			b, err := ioutil.ReadFile(filepath.Join(dir, name))
+			assert.Nil(t, err)
+			for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+				var m map[string]interface{}
+				assert.Nil(t, json.Unmarshal([]byte(line), &m))
+				lines++
+			}
+		}
+		assert.Equal(t, lines, 400)
+	})
+}
+
+func TestParseRotation(t *testing.T) {
+	r, err := log.ParseRotation("Hourly")
+	assert.Nil(t, err)
+	assert.Equal(t, r, log.RotateHourly)
+	_, err = log.ParseRotation("weekly")
+	assert.Error(t, err, "invalid rotation \"weekly\"")
+}
@@ -47,15 +47,19 @@ func (c *consumer) Topics() []string {
 	return c.topics
 }
 
-func (c *consumer) Consume(ctx context.Context, msg Message) error {
+func (c *consumer) Consume(ctx context.Context, msg Message) (err error) {
+
+	ctx, span := StartConsume(ctx, msg)
+	defer func() { span.SetError(err).End() }()
+
 	e := reflect.New(c.e.Elem())
-	err := json.Unmarshal(msg.Body(), e.Interface())
+	err = json.Unmarshal(msg.Body(), e.Interface())
 	if err != nil {
 		return err
 	}
 	out := c.v.Call([]reflect.Value{reflect.ValueOf(ctx), e})
-	if err = out[0].Interface().(error); err != nil {
-		return err
+	if o := out[0].Interface(); o != nil {
+		return o.(error)
 	}
 	return nil
 }
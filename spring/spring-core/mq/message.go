@@ -24,6 +24,12 @@ type Message interface {
 	Extra() map[string]string
 }
 
+// ExtraSetter 可以设置额外信息的消息。Extra 返回 nil 的消息需要实现该接口，否则
+// StartSend 无法将链路信息写入消息。
+type ExtraSetter interface {
+	SetExtra(key, value string)
+}
+
 type message struct {
 	topic string            // 消息主题
 	id    string            // Key
@@ -82,3 +88,8 @@ func (msg *message) WithExtra(key, value string) *message {
 	msg.extra[key] = value
 	return msg
 }
+
+// SetExtra 为消息添加额外的信息。
+func (msg *message) SetExtra(key, value string) {
+	msg.WithExtra(key, value)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq
+
+import (
+	"context"
+
+	"github.com/go-spring/spring-core/log"
+	"github.com/go-spring/spring-core/trace"
+)
+
+// extraCarrier 使用消息的额外信息传递链路信息。
+type extraCarrier struct{ msg Message }
+
+func (c extraCarrier) Get(key string) string {
+	return c.msg.Extra()[key]
+}
+
+func (c extraCarrier) Set(key, value string) {
+	if s, ok := c.msg.(ExtraSetter); ok {
+		s.SetExtra(key, value)
+	} else if m := c.msg.Extra(); m != nil {
+		m[key] = value
+	} else {
+		log.Warnf("message %T of topic %s can't carry %s, implement mq.ExtraSetter", c.msg, c.msg.Topic(), key)
+	}
+}
+
+// StartSend 创建发送消息的 span ，然后将链路信息写入消息的额外信息。Producer 的
+// 实现应该在 SendMessage 方法中调用该函数，并在发送结束后调用 span 的 End 方法。
+func StartSend(ctx context.Context, msg Message) (context.Context, *trace.Span) {
+	ctx, span := trace.Start(ctx, "send "+msg.Topic(), trace.WithKind(trace.KindProducer))
+	trace.Inject(ctx, extraCarrier{msg})
+	return ctx, span
+}
+
+// StartConsume 从消息的额外信息中提取上游的链路信息，然后创建消费消息的 span 。
+// Consumer 的实现应该在 Consume 方法中调用该函数，并在消费结束后调用 span 的
+// End 方法。
+func StartConsume(ctx context.Context, msg Message) (context.Context, *trace.Span) {
+	ctx = trace.Extract(ctx, extraCarrier{msg})
+	return trace.Start(ctx, "consume "+msg.Topic(), trace.WithKind(trace.KindConsumer))
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-spring/spring-core/log"
+	"github.com/go-spring/spring-core/mq"
+	"github.com/go-spring/spring-core/trace"
+	"github.com/go-spring/spring-stl/assert"
+)
+
+type readonlyMessage struct{ topic string }
+
+func (m readonlyMessage) Topic() string            { return m.topic }
+func (m readonlyMessage) ID() string               { return "" }
+func (m readonlyMessage) Body() []byte             { return nil }
+func (m readonlyMessage) Extra() map[string]string { return nil }
+
+func TestStartSend(t *testing.T) {
+
+	msg := mq.NewMessage().WithTopic("order")
+	_, send := mq.StartSend(context.Background(), msg)
+	send.End()
+	assert.NotEqual(t, msg.Extra()[trace.Header], "")
+
+	_, span := mq.StartConsume(context.Background(), msg)
+	span.End()
+	assert.Equal(t, span.TraceID(), send.TraceID())
+
+	var buf bytes.Buffer
+	log.SetOutput(log.Text(&buf))
+	defer log.Reset()
+
+	_, span = mq.StartSend(context.Background(), readonlyMessage{"order"})
+	span.End()
+	assert.Matches(t, buf.String(), "message mq_test.readonlyMessage of topic order can't carry traceparent")
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// encrypt 命令生成 AES-GCM 密钥或者将属性值加密成 ENC(...) 的形式，密钥来自
+// SPRING_CONFIG_ENCRYPT_KEY 环境变量或者 -key-file 指定的文件，属性值来自命令
+// 行参数，没有参数时从标准输入读取。
+//
+//	encrypt -genkey > app.key
+//	encrypt -key-file app.key 123456
+//	echo -n 123456 | SPRING_CONFIG_ENCRYPT_KEY=... encrypt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/go-spring/spring-core/conf/aesgcm"
+	"github.com/go-spring/spring-core/gs/environ"
+)
+
+func main() {
+	genKey := flag.Bool("genkey", false, "generate a base64 encoded AES-256 key")
+	keyFile := flag.String("key-file", "", "file that contains the base64 encoded key")
+	flag.Parse()
+	if err := run(*genKey, *keyFile, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(genKey bool, keyFile string, args []string) error {
+
+	if genKey {
+		key, err := aesgcm.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	}
+
+	var (
+		c   *aesgcm.Cipher
+		err error
+	)
+	if keyFile != "" {
+		c, err = aesgcm.NewFromFile(keyFile)
+	} else {
+		c, err = aesgcm.NewFromEnv(environ.SpringConfigEncryptKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	values := args
+	if len(values) == 0 {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		values = []string{strings.TrimRight(string(b), "\r\n")}
+	}
+
+	for _, v := range values {
+		s, err := c.Encrypt(v)
+		if err != nil {
+			return err
+		}
+		fmt.Println(s)
+	}
+	return nil
+}
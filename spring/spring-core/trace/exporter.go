@@ -0,0 +1,124 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Exporter 上报已经结束的 span 。
+type Exporter interface {
+	Export(span *SpanData) error
+}
+
+var config = struct {
+	mutex    sync.RWMutex
+	exporter Exporter
+}{}
+
+// SetExporter 设置 span 的上报方式，为 nil 时不上报，但仍然会生成链路信息。
+func SetExporter(e Exporter) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+	config.exporter = e
+}
+
+func getExporter() Exporter {
+	config.mutex.RLock()
+	defer config.mutex.RUnlock()
+	return config.exporter
+}
+
+// WriterExporter 将 span 编码成 JSON 格式然后按行写入 io.Writer 。
+type WriterExporter struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// NewWriterExporter 返回写入 w 的 WriterExporter 对象。
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{w: w}
+}
+
+// NewStdoutExporter 返回写入标准输出的 WriterExporter 对象。
+func NewStdoutExporter() *WriterExporter {
+	return NewWriterExporter(os.Stdout)
+}
+
+// NewFileExporter 返回追加写入 file 文件的 WriterExporter 对象，文件不存在时
+// 会自动创建。
+func NewFileExporter(file string) (*WriterExporter, error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterExporter(f), nil
+}
+
+func (e *WriterExporter) Export(span *SpanData) error {
+	b, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
+
+// Close 关闭底层的 io.Writer ，如果它实现了 io.Closer 接口。
+func (e *WriterExporter) Close() error {
+	if c, ok := e.w.(io.Closer); ok && e.w != os.Stdout {
+		return c.Close()
+	}
+	return nil
+}
+
+// MemoryExporter 将 span 保存在内存中，主要用于测试。
+type MemoryExporter struct {
+	mutex sync.Mutex
+	spans []SpanData
+}
+
+// NewMemoryExporter 返回一个空的 MemoryExporter 对象。
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+func (e *MemoryExporter) Export(span *SpanData) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = append(e.spans, *span)
+	return nil
+}
+
+// Spans 返回已经上报的所有 span ，按照结束的先后顺序排列。
+func (e *MemoryExporter) Spans() []SpanData {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset 清空已经上报的 span 。
+func (e *MemoryExporter) Reset() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = nil
+}
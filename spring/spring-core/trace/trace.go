@@ -0,0 +1,373 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package trace 提供了分布式链路追踪的抽象，使用 W3C traceparent 格式在 Web、
+// gRPC 以及 MQ 之间传递链路信息，并且支持通过 Exporter 扩展 span 的上报方式。
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header 传递链路信息使用的 W3C 标准头部。
+const Header = "traceparent"
+
+// spanKey 当前活跃的 span 在 context.Context 中的 key 。
+type spanKey struct{}
+
+// remoteKey 从上游提取的 SpanContext 在 context.Context 中的 key 。
+type remoteKey struct{}
+
+// TraceID 链路 ID ，16 字节。
+type TraceID [16]byte
+
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID 片段 ID ，8 字节。
+type SpanID [8]byte
+
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext 可以跨进程传递的 span 信息。
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid 返回 TraceID 和 SpanID 是否都有效。
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent 返回 W3C traceparent 格式的字符串，如
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 。
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent 解析 W3C traceparent 格式的字符串。
+func ParseTraceparent(s string) (SpanContext, error) {
+
+	ss := strings.Split(strings.TrimSpace(s), "-")
+	if len(ss) < 4 || len(ss[0]) != 2 || ss[0] == "ff" {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	// 版本 00 必须严格包含四个部分，更高的版本允许在后面追加内容。
+	if ss[0] == "00" && len(ss) != 4 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	var sc SpanContext
+	if err := decodeHex(ss[1], sc.TraceID[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	if err := decodeHex(ss[2], sc.SpanID[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	var flags [1]byte
+	if err := decodeHex(ss[3], flags[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	sc.Sampled = flags[0]&0x01 == 0x01
+	return sc, nil
+}
+
+func decodeHex(s string, b []byte) error {
+	if len(s) != hex.EncodedLen(len(b)) || strings.ToLower(s) != s {
+		return errors.New("invalid hex string")
+	}
+	_, err := hex.Decode(b, []byte(s))
+	return err
+}
+
+// Kind span 的类型。
+type Kind int
+
+const (
+	KindInternal = Kind(0) // 进程内部的调用
+	KindServer   = Kind(1) // 处理远程请求
+	KindClient   = Kind(2) // 发起远程请求
+	KindProducer = Kind(3) // 发送消息
+	KindConsumer = Kind(4) // 消费消息
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	case KindProducer:
+		return "producer"
+	case KindConsumer:
+		return "consumer"
+	}
+	return "internal"
+}
+
+// Span 链路中的一个片段。
+type Span struct {
+	mutex sync.Mutex
+
+	ended  bool
+	record SpanData
+}
+
+// SpanData 导出给 Exporter 的 span 数据。
+type SpanData struct {
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind"`
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	StartTime  time.Time         `json:"start_time"`
+	EndTime    time.Time         `json:"end_time"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	spanContext SpanContext
+}
+
+// SpanContext 返回 span 的可传递信息。
+func (d *SpanData) SpanContext() SpanContext {
+	return d.spanContext
+}
+
+// SpanContext 返回 span 的可传递信息。
+func (s *Span) SpanContext() SpanContext {
+	return s.record.spanContext
+}
+
+// TraceID 返回 span 所属链路的 ID 。
+func (s *Span) TraceID() string {
+	return s.record.TraceID
+}
+
+// SpanID 返回 span 的 ID 。
+func (s *Span) SpanID() string {
+	return s.record.SpanID
+}
+
+// SetAttribute 设置 span 的属性。
+func (s *Span) SetAttribute(key, value string) *Span {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.record.Attributes == nil {
+		s.record.Attributes = make(map[string]string)
+	}
+	s.record.Attributes[key] = value
+	return s
+}
+
+// SetError 记录 span 执行过程中发生的错误。
+func (s *Span) SetError(err error) *Span {
+	if err != nil {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.record.Error = err.Error()
+	}
+	return s
+}
+
+// End 结束 span ，然后交给 Exporter 上报。重复调用 End 方法是安全的，只有第一次
+// 调用有效。
+func (s *Span) End() {
+
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.record.EndTime = time.Now()
+	data := s.record
+	s.mutex.Unlock()
+
+	if data.spanContext.Sampled {
+		if e := getExporter(); e != nil {
+			_ = e.Export(&data)
+		}
+	}
+}
+
+type startArg struct {
+	kind  Kind
+	attrs map[string]string
+}
+
+type StartOption func(arg *startArg)
+
+// WithKind 设置 span 的类型。
+func WithKind(kind Kind) StartOption {
+	return func(arg *startArg) {
+		arg.kind = kind
+	}
+}
+
+// WithAttribute 设置 span 的属性。
+func WithAttribute(key, value string) StartOption {
+	return func(arg *startArg) {
+		if arg.attrs == nil {
+			arg.attrs = make(map[string]string)
+		}
+		arg.attrs[key] = value
+	}
+}
+
+// Start 创建一个新的 span ，返回的 ctx 携带该 span 作为当前活跃的 span 。如果
+// ctx 中已有活跃的 span 则新 span 作为其子节点，否则如果 ctx 中携带了从上游提取
+// 的 SpanContext 则新 span 延续上游的链路，都没有时创建新的链路。传入的 ctx 不
+// 会被修改，因此从同一个 ctx 并发创建的 span 都是它的子节点，互不影响。
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+
+	arg := startArg{}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var parent SpanContext
+	if prev := FromContext(ctx); prev != nil {
+		parent = prev.SpanContext()
+	} else if sc, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+		parent = sc
+	}
+
+	sc := SpanContext{SpanID: newSpanID(), Sampled: true}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = newTraceID()
+	}
+
+	s := &Span{}
+	s.record = SpanData{
+		Name:        name,
+		Kind:        arg.kind.String(),
+		TraceID:     sc.TraceID.String(),
+		SpanID:      sc.SpanID.String(),
+		StartTime:   time.Now(),
+		Attributes:  arg.attrs,
+		spanContext: sc,
+	}
+	if parent.IsValid() {
+		s.record.ParentID = parent.SpanID.String()
+	}
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext 返回 ctx 中当前活跃的 span ，没有时返回 nil 。
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SpanContextFromContext 返回 ctx 中当前活跃的 span 的信息，没有活跃的 span
+// 时返回从上游提取的 SpanContext 。
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s := FromContext(ctx); s != nil {
+		return s.SpanContext()
+	}
+	if ctx != nil {
+		if sc, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+			return sc
+		}
+	}
+	return SpanContext{}
+}
+
+// Carrier 链路信息的载体，比如 http.Header 、gRPC metadata 、MQ 消息的额外
+// 信息等。
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// MapCarrier 基于 map[string]string 的 Carrier 实现。
+type MapCarrier map[string]string
+
+func (c MapCarrier) Get(key string) string { return c[key] }
+
+func (c MapCarrier) Set(key, value string) { c[key] = value }
+
+// Inject 将 ctx 中的链路信息写入 carrier 。
+func Inject(ctx context.Context, carrier Carrier) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		carrier.Set(Header, sc.Traceparent())
+	}
+}
+
+// Extract 从 carrier 中提取上游的链路信息，然后返回携带该信息的 ctx ，之后通过
+// Start 创建的 span 会延续上游的链路。carrier 中没有有效的链路信息时返回原 ctx 。
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	s := carrier.Get(Header)
+	if s == "" {
+		return ctx
+	}
+	sc, err := ParseTraceparent(s)
+	if err != nil {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-spring/spring-core/trace"
+	"github.com/go-spring/spring-stl/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+
+	s := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := trace.ParseTraceparent(s)
+	assert.Nil(t, err)
+	assert.Equal(t, sc.TraceID.String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Equal(t, sc.SpanID.String(), "00f067aa0ba902b7")
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, sc.Traceparent(), s)
+
+	for _, s = range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-00",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+	} {
+		_, err = trace.ParseTraceparent(s)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestStart(t *testing.T) {
+
+	e := trace.NewMemoryExporter()
+	trace.SetExporter(e)
+	defer trace.SetExporter(nil)
+
+	t.Run("new trace", func(t *testing.T) {
+		defer e.Reset()
+
+		ctx, parent := trace.Start(context.Background(), "parent")
+		assert.True(t, trace.FromContext(ctx) == parent)
+
+		childCtx, child := trace.Start(ctx, "child", trace.WithKind(trace.KindClient))
+		assert.True(t, trace.FromContext(childCtx) == child)
+		assert.True(t, trace.FromContext(ctx) == parent)
+		assert.Equal(t, child.TraceID(), parent.TraceID())
+
+		child.SetError(errors.New("error"))
+		child.End()
+		child.End()
+		assert.True(t, trace.FromContext(ctx) == parent)
+
+		parent.End()
+
+		spans := e.Spans()
+		assert.Equal(t, len(spans), 2)
+		assert.Equal(t, spans[0].Name, "child")
+		assert.Equal(t, spans[0].Kind, "client")
+		assert.Equal(t, spans[0].Error, "error")
+		assert.Equal(t, spans[0].ParentID, parent.SpanID())
+		assert.Equal(t, spans[1].Name, "parent")
+		assert.Equal(t, spans[1].ParentID, "")
+	})
+
+	t.Run("siblings", func(t *testing.T) {
+		defer e.Reset()
+
+		ctx, parent := trace.Start(context.Background(), "parent")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c, child := trace.Start(ctx, "child")
+				_, grandson := trace.Start(c, "grandson")
+				grandson.End()
+				child.End()
+			}()
+		}
+		wg.Wait()
+		parent.End()
+
+		spans := e.Spans()
+		assert.Equal(t, len(spans), 21)
+		children := make(map[string]bool)
+		for _, s := range spans {
+			if s.Name == "child" {
+				assert.Equal(t, s.ParentID, parent.SpanID())
+				children[s.SpanID] = true
+			}
+		}
+		assert.Equal(t, len(children), 10)
+		for _, s := range spans {
+			if s.Name == "grandson" {
+				assert.True(t, children[s.ParentID])
+			}
+		}
+	})
+
+	t.Run("not sampled", func(t *testing.T) {
+		defer e.Reset()
+
+		ctx := trace.Extract(context.Background(), trace.MapCarrier{
+			trace.Header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		})
+		_, span := trace.Start(ctx, "span")
+		span.End()
+		assert.Equal(t, span.TraceID(), "4bf92f3577b34da6a3ce929d0e0e4736")
+		assert.Equal(t, len(e.Spans()), 0)
+	})
+}
+
+func TestPropagation(t *testing.T) {
+
+	carrier := trace.MapCarrier{}
+	trace.Inject(context.Background(), carrier)
+	assert.Equal(t, len(carrier), 0)
+
+	ctx, client := trace.Start(context.Background(), "client")
+	trace.Inject(ctx, carrier)
+	assert.Equal(t, carrier[trace.Header], client.SpanContext().Traceparent())
+
+	ctx = trace.Extract(context.Background(), carrier)
+	assert.Equal(t, trace.SpanContextFromContext(ctx), client.SpanContext())
+
+	ctx, server := trace.Start(ctx, "server")
+	assert.Equal(t, server.TraceID(), client.TraceID())
+	assert.NotEqual(t, server.SpanID(), client.SpanID())
+
+	ctx = trace.Extract(context.Background(), trace.MapCarrier{trace.Header: "invalid"})
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestWriterExporter(t *testing.T) {
+
+	buf := bytes.NewBuffer(nil)
+	trace.SetExporter(trace.NewWriterExporter(buf))
+	defer trace.SetExporter(nil)
+
+	_, span := trace.Start(context.Background(), "span", trace.WithAttribute("a", "b"))
+	span.End()
+
+	var m map[string]interface{}
+	err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &m)
+	assert.Nil(t, err)
+	assert.Equal(t, m["name"], "span")
+	assert.Equal(t, m["trace_id"], span.TraceID())
+	assert.Equal(t, m["attributes"], map[string]interface{}{"a": "b"})
+}
@@ -22,9 +22,11 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/go-spring/spring-core/log"
+	"github.com/go-spring/spring-core/trace"
 	"github.com/go-spring/spring-stl/util"
 )
 
@@ -256,3 +258,15 @@ var defaultLoggerFilter = Filter(FuncFilter(func(ctx Context, chain FilterChain)
 	w := ctx.ResponseWriter()
 	log.Ctx(ctx.Context()).Infof("cost:%v size:%d code:%d %s", time.Since(start), w.Size(), w.Status(), string(w.Body()))
 }))
+
+// TraceFilter 全局的链路追踪过滤器，从请求头部提取上游的 traceparent 信息，然后
+// 创建处理该请求的 span ，该过滤器应该排在日志过滤器之前，这样日志才能输出链路信息。
+var TraceFilter = Filter(FuncFilter(func(ctx Context, chain FilterChain) {
+	req := ctx.Request()
+	c := trace.Extract(req.Context(), req.Header)
+	c, span := trace.Start(c, req.Method+" "+ctx.Path(), trace.WithKind(trace.KindServer))
+	defer span.End()
+	ctx.SetRequest(req.WithContext(c))
+	chain.Next(ctx)
+	span.SetAttribute("http.status_code", strconv.Itoa(ctx.ResponseWriter().Status()))
+}))
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-core/trace"
+	"github.com/go-spring/spring-core/web"
+	"github.com/go-spring/spring-stl/assert"
+)
+
+type traceResponseWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w traceResponseWriter) Status() int  { return w.Code }
+func (w traceResponseWriter) Size() int    { return w.ResponseRecorder.Body.Len() }
+func (w traceResponseWriter) Body() []byte { return w.ResponseRecorder.Body.Bytes() }
+
+// webContext 嵌入时使用别名，避免字段名和 Context 方法冲突。
+type webContext = web.Context
+
+// traceContext 只实现 TraceFilter 用到的方法。
+type traceContext struct {
+	webContext
+	req *http.Request
+	w   traceResponseWriter
+}
+
+func (c *traceContext) Request() *http.Request             { return c.req }
+func (c *traceContext) SetRequest(r *http.Request)         { c.req = r }
+func (c *traceContext) Context() context.Context           { return c.req.Context() }
+func (c *traceContext) Path() string                       { return c.req.URL.Path }
+func (c *traceContext) ResponseWriter() web.ResponseWriter { return c.w }
+
+func TestTraceFilter(t *testing.T) {
+
+	e := trace.NewMemoryExporter()
+	trace.SetExporter(e)
+	defer trace.SetExporter(nil)
+
+	// 模拟上游服务发起请求，将链路信息注入请求头部。
+	clientCtx, client := trace.Start(context.Background(), "client", trace.WithKind(trace.KindClient))
+	req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	trace.Inject(clientCtx, req.Header)
+
+	var server *trace.Span
+	handler := web.HandlerFilter(web.FUNC(func(ctx web.Context) {
+		server = trace.FromContext(ctx.Context())
+		ctx.ResponseWriter().WriteHeader(http.StatusAccepted)
+	}))
+
+	ctx := &traceContext{req: req, w: traceResponseWriter{httptest.NewRecorder()}}
+	web.NewDefaultFilterChain([]web.Filter{web.TraceFilter, handler}).Next(ctx)
+	client.End()
+
+	assert.NotNil(t, server)
+	assert.Equal(t, server.TraceID(), client.TraceID())
+	assert.NotEqual(t, server.SpanID(), client.SpanID())
+
+	spans := e.Spans()
+	assert.Equal(t, len(spans), 2)
+	assert.Equal(t, spans[0].Name, "GET /hello")
+	assert.Equal(t, spans[0].Kind, "server")
+	assert.Equal(t, spans[0].ParentID, client.SpanID())
+	assert.Equal(t, spans[0].Attributes["http.status_code"], "202")
+	assert.True(t, trace.FromContext(req.Context()) == nil)
+}
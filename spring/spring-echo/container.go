@@ -85,7 +85,7 @@ func (c *Container) Start() error {
 			}
 
 			chain := web.NewDefaultFilterChain([]web.Filter{
-				loggerFilter, recoveryFilter,
+				web.TraceFilter, loggerFilter, recoveryFilter,
 				web.HandlerFilter(Handler(next)),
 			})
 			chain.Next(WebContext(echoCtx))
@@ -84,7 +84,7 @@ func (c *Container) Start() error {
 	loggerFilter := c.GetLoggerFilter()
 	recoveryFilter := &recoveryFilter{}
 
-	for _, filter := range []web.Filter{loggerFilter, recoveryFilter} {
+	for _, filter := range []web.Filter{web.TraceFilter, loggerFilter, recoveryFilter} {
 		f := filter // 避免延迟绑定
 		c.ginEngine.Use(func(ginCtx *gin.Context) {
 			f.Invoke(WebContext(ginCtx), &ginFilterChain{ginCtx})
@@ -18,10 +18,13 @@ package GrpcClientFactory
 
 import (
 	"github.com/go-spring/starter-core"
+	"github.com/go-spring/starter-grpc/interceptor"
 	"google.golang.org/grpc"
 )
 
 // NewClient 根据配置创建 grpc.ClientConnInterface 对象
 func NewClient(config StarterCore.GrpcEndpointConfig) (grpc.ClientConnInterface, error) {
-	return grpc.Dial(config.Address, grpc.WithInsecure())
+	return grpc.Dial(config.Address, grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(GrpcInterceptor.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(GrpcInterceptor.StreamClientInterceptor()))
 }
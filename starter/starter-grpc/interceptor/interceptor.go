@@ -0,0 +1,107 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package GrpcInterceptor 提供通过 gRPC metadata 传递 traceparent 链路信息的拦截器。
+package GrpcInterceptor
+
+import (
+	"context"
+
+	"github.com/go-spring/spring-core/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// mdCarrier 使用 gRPC metadata 传递链路信息。
+type mdCarrier metadata.MD
+
+func (c mdCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c mdCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// extract 从请求的 metadata 中提取上游的链路信息，然后创建处理请求的 span 。
+func extract(ctx context.Context, method string) (context.Context, *trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = trace.Extract(ctx, mdCarrier(md))
+	}
+	return trace.Start(ctx, method, trace.WithKind(trace.KindServer))
+}
+
+// inject 创建发起请求的 span ，然后将链路信息写入请求的 metadata 。
+func inject(ctx context.Context, method string) (context.Context, *trace.Span) {
+	ctx, span := trace.Start(ctx, method, trace.WithKind(trace.KindClient))
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	trace.Inject(ctx, mdCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// UnaryServerInterceptor 服务端一元调用的链路追踪拦截器。
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx, span := extract(ctx, info.FullMethod)
+		defer func() { span.SetError(err).End() }()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 服务端流式调用的链路追踪拦截器。
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, span := extract(ss.Context(), info.FullMethod)
+		defer func() { span.SetError(err).End() }()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream 替换 grpc.ServerStream 的 context.Context 对象。
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor 客户端一元调用的链路追踪拦截器。
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+		ctx, span := inject(ctx, method)
+		defer func() { span.SetError(err).End() }()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor 客户端流式调用的链路追踪拦截器，span 在创建流之后结束。
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (_ grpc.ClientStream, err error) {
+		ctx, span := inject(ctx, method)
+		defer func() { span.SetError(err).End() }()
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package GrpcInterceptor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-spring/spring-core/trace"
+	"github.com/go-spring/spring-stl/assert"
+	"github.com/go-spring/starter-grpc/interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// transfer 模拟网络传输，将客户端发出的 metadata 转换为服务端收到的 metadata 。
+func transfer(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+func TestUnaryInterceptor(t *testing.T) {
+
+	e := trace.NewMemoryExporter()
+	trace.SetExporter(e)
+	defer trace.SetExporter(nil)
+
+	ctx, parent := trace.Start(context.Background(), "parent")
+	ctx = metadata.AppendToOutgoingContext(ctx, "user", "go-spring")
+
+	var client, server *trace.Span
+	serverInterceptor := GrpcInterceptor.UnaryServerInterceptor()
+	serverInfo := &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		server = trace.FromContext(ctx)
+		md, _ := metadata.FromIncomingContext(ctx)
+		assert.Equal(t, md.Get("user"), []string{"go-spring"})
+		return nil, errors.New("not found")
+	}
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		client = trace.FromContext(ctx)
+		_, err := serverInterceptor(transfer(ctx), req, serverInfo, handler)
+		return err
+	}
+
+	clientInterceptor := GrpcInterceptor.UnaryClientInterceptor()
+	err := clientInterceptor(ctx, "/helloworld.Greeter/SayHello", nil, nil, nil, invoker)
+	assert.Error(t, err, "not found")
+	parent.End()
+
+	assert.Equal(t, client.TraceID(), parent.TraceID())
+	assert.Equal(t, server.TraceID(), parent.TraceID())
+	assert.True(t, trace.FromContext(ctx) == parent)
+
+	spans := e.Spans()
+	assert.Equal(t, len(spans), 3)
+	assert.Equal(t, spans[0].Kind, "server")
+	assert.Equal(t, spans[0].ParentID, client.SpanID())
+	assert.Equal(t, spans[0].Error, "not found")
+	assert.Equal(t, spans[1].Kind, "client")
+	assert.Equal(t, spans[1].ParentID, parent.SpanID())
+	assert.Equal(t, spans[1].Error, "not found")
+}
+
+func TestStreamInterceptor(t *testing.T) {
+
+	e := trace.NewMemoryExporter()
+	trace.SetExporter(e)
+	defer trace.SetExporter(nil)
+
+	var client, server *trace.Span
+	serverInterceptor := GrpcInterceptor.StreamServerInterceptor()
+	serverInfo := &grpc.StreamServerInfo{FullMethod: "/helloworld.Greeter/SayHelloStream"}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		server = trace.FromContext(ss.Context())
+		return nil
+	}
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		client = trace.FromContext(ctx)
+		ss := &serverStream{ctx: transfer(ctx)}
+		return nil, serverInterceptor(nil, ss, serverInfo, handler)
+	}
+
+	clientInterceptor := GrpcInterceptor.StreamClientInterceptor()
+	_, err := clientInterceptor(context.Background(), &grpc.StreamDesc{}, nil, "/helloworld.Greeter/SayHelloStream", streamer)
+	assert.Nil(t, err)
+
+	assert.NotNil(t, server)
+	assert.Equal(t, server.TraceID(), client.TraceID())
+
+	spans := e.Spans()
+	assert.Equal(t, len(spans), 2)
+	assert.Equal(t, spans[0].Kind, "server")
+	assert.Equal(t, spans[0].ParentID, client.SpanID())
+	assert.Equal(t, spans[1].Kind, "client")
+	assert.Equal(t, spans[1].ParentID, "")
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/go-spring/spring-core/log"
 	"github.com/go-spring/spring-stl/util"
 	"github.com/go-spring/starter-core"
+	"github.com/go-spring/starter-grpc/interceptor"
 	"google.golang.org/grpc"
 )
 
@@ -41,7 +42,10 @@ type Starter struct {
 func NewStarter(config StarterCore.GrpcServerConfig) *Starter {
 	return &Starter{
 		config: config,
-		server: grpc.NewServer(),
+		server: grpc.NewServer(
+			grpc.UnaryInterceptor(GrpcInterceptor.UnaryServerInterceptor()),
+			grpc.StreamInterceptor(GrpcInterceptor.StreamServerInterceptor()),
+		),
 	}
 }
 
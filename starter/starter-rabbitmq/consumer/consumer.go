@@ -22,6 +22,7 @@ import (
 	"github.com/go-spring/spring-core/gs"
 	"github.com/go-spring/spring-core/log"
 	"github.com/go-spring/spring-core/mq"
+	"github.com/go-spring/spring-stl/knife"
 	"github.com/go-spring/spring-stl/util"
 	"github.com/go-spring/starter-rabbitmq/server"
 )
@@ -75,8 +76,13 @@ func (starter *Starter) OnStartApp(ctx gs.AppContext) {
 			}
 			d := <-delivery
 			msg := mq.NewMessage().WithBody(d.Body).WithTopic(topic)
+			for k, v := range d.Headers {
+				if s, ok := v.(string); ok {
+					msg.WithExtra(k, s)
+				}
+			}
 			for _, c := range consumers {
-				c.Consume(context.TODO(), msg)
+				c.Consume(knife.New(context.Background()), msg)
 			}
 		}
 	}()
@@ -33,13 +33,23 @@ type Sender struct {
 	Server *StarterRabbitMQServer.AMQPServer `autowire:""`
 }
 
-func (sender *Sender) SendMessage(ctx context.Context, msg mq.Message) error {
+func (sender *Sender) SendMessage(ctx context.Context, msg mq.Message) (err error) {
+
+	_, span := mq.StartSend(ctx, msg)
+	defer func() { span.SetError(err).End() }()
+
+	headers := amqp.Table{}
+	for k, v := range msg.Extra() {
+		headers[k] = v
+	}
+
 	return sender.Server.Channel.Publish(
 		"",          // exchange
 		msg.Topic(), // routing key
 		false,       // mandatory
 		false,       // immediate
 		amqp.Publishing{
+			Headers:     headers,
 			ContentType: "text/plain",
 			Body:        msg.Body(),
 		})